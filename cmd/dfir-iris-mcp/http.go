@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/config"
+	"dfir-iris-mcp/internal/telemetry"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// shutdownTimeout bounds how long in-flight tool calls may run after a
+// shutdown signal before the listener is torn down.
+const shutdownTimeout = 15 * time.Second
+
+// serveHTTP serves s until ctx is cancelled. Streamable HTTP clients connect
+// to /mcp, legacy SSE clients to /sse, and /healthz reports liveness.
+func serveHTTP(ctx context.Context, s *mcp.Server, cfg *config.Config) error {
+	getServer := func(*http.Request) *mcp.Server { return s }
+
+	var streamable, sse http.Handler
+	streamable = mcp.NewStreamableHTTPHandler(getServer, &mcp.StreamableHTTPOptions{
+		SessionTimeout: cfg.SessionTimeout,
+	})
+	sse = mcp.NewSSEHandler(getServer, nil)
+	if cfg.SessionAuth {
+		streamable = sessionCredentials(streamable)
+		sse = sessionCredentials(sse)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", streamable)
+	mux.Handle("/sse", sse)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		n := 0
+		for range s.Sessions() {
+			n++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","sessions":%d}`, n)
+	})
+
+	streams, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+
+	srv := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           detachStreams(streams, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("serving MCP over HTTP", "url", "http://"+cfg.Listen+"/mcp", "sse", "/sse")
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Hanging GET streams never go idle, so end them first; Shutdown then
+	// only waits for POSTs carrying in-flight tool calls.
+	closeStreams()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	for ss := range s.Sessions() {
+		ss.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// serveMetrics serves the Prometheus metrics on /metrics at addr until ctx
+// is cancelled.
+func serveMetrics(ctx context.Context, addr string, m *telemetry.Metrics) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	stop := context.AfterFunc(ctx, func() { srv.Close() })
+	defer stop()
+	slog.Info("serving metrics", "url", "http://"+addr+"/metrics")
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// sessionKeyExtra is the TokenInfo.Extra key holding the caller's IRIS key.
+const sessionKeyExtra = "iris_api_key"
+
+// sessionCredentials requires a DFIR-IRIS API key as the Bearer token of
+// every request and makes it the key used for IRIS calls in that session.
+//
+// A session is connected with the context of the request that created it, so
+// the key attached here applies to all of the session's tool calls. The token
+// fingerprint is recorded as the session's user, which makes the SDK reject
+// later requests on the same session that present a different key.
+func sessionCredentials(next http.Handler) http.Handler {
+	attach := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, _ := auth.TokenInfoFromContext(r.Context()).Extra[sessionKeyExtra].(string)
+		next.ServeHTTP(w, r.WithContext(client.WithAPIKey(r.Context(), key)))
+	})
+	return auth.RequireBearerToken(verifyAPIKey, nil)(attach)
+}
+
+// verifyAPIKey accepts any non-empty key; IRIS itself authenticates it and
+// enforces the owner's permissions on each call.
+func verifyAPIKey(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+	if token == "" {
+		return nil, auth.ErrInvalidToken
+	}
+	sum := sha256.Sum256([]byte(token))
+	return &auth.TokenInfo{
+		UserID:     hex.EncodeToString(sum[:16]),
+		Expiration: time.Now().Add(24 * time.Hour),
+		Extra:      map[string]any{sessionKeyExtra: token},
+	}, nil
+}
+
+// detachStreams cancels long-lived GET requests (SSE streams) when streams is
+// done, leaving other requests to finish under http.Server.Shutdown.
+func detachStreams(streams context.Context, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(streams, cancel)
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -2,31 +2,296 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"regexp"
+	"strings"
+	"syscall"
 
+	"dfir-iris-mcp/internal/audit"
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/completion"
 	"dfir-iris-mcp/internal/config"
+	"dfir-iris-mcp/internal/logging"
+	"dfir-iris-mcp/internal/prompts"
+	"dfir-iris-mcp/internal/redact"
+	"dfir-iris-mcp/internal/resources"
+	"dfir-iris-mcp/internal/telemetry"
 	"dfir-iris-mcp/internal/tools"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// version is reported to MCP clients and in traces.
+const version = "1.0.0"
+
 func main() {
-	cfg, err := config.Load()
+	listen := flag.String("listen", "", "serve MCP over HTTP on this address (e.g. :8080) instead of stdio")
+	configPath := flag.String("config", os.Getenv("DFIR_IRIS_CONFIG"), "path to a YAML configuration file")
+	profile := flag.String("profile", os.Getenv("DFIR_IRIS_PROFILE"), "named profile from the config file to use")
+	verifyAudit := flag.String("verify-audit", "", "check the hash chain of this audit log (- for stdin) and exit")
+	flag.Parse()
+
+	if *verifyAudit != "" {
+		if err := verifyAuditLog(*verifyAudit); err != nil {
+			fatal("verifying audit log", "error", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load(*configPath, *profile)
 	if err != nil {
-		log.Fatalf("config: %v", err)
+		fatal("loading configuration", "error", err)
+	}
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("loading configuration", "error", err)
+	}
+	redactor, err := newRedactor(cfg.Redaction)
+	if err != nil {
+		fatal("loading configuration", "error", err)
+	}
+	logger = slog.New(logging.Redact(logger.Handler(), redactor.String))
+	slog.SetDefault(logger)
+	if *listen != "" {
+		cfg.Listen = *listen
+	}
+	if cfg.SessionAuth && cfg.Listen == "" {
+		fatal("DFIR_IRIS_SESSION_AUTH requires --listen")
+	}
+	if cfg.Audit.Path == "-" && cfg.Listen == "" {
+		fatal("an audit log on stdout requires --listen, as stdio serves MCP")
+	}
+
+	if cfg.Telemetry.Tracing != "" {
+		shutdown, err := telemetry.StartTracing(context.Background(), cfg.Telemetry.Tracing, version)
+		if err != nil {
+			fatal("starting tracing", "error", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				slog.Error("flushing traces", "error", err)
+			}
+		}()
+	}
+	var metrics *telemetry.Metrics
+	if cfg.Telemetry.MetricsListen != "" {
+		metrics = telemetry.NewMetrics()
 	}
 
-	c := client.New(cfg.BaseURL, cfg.APIKey)
+	c, err := newClient(cfg, metrics)
+	if err != nil {
+		fatal("creating IRIS client", "error", err)
+	}
+	var instances []tools.Instance
+	for name, peer := range cfg.Peers {
+		pc, err := newClient(peer, metrics)
+		if err != nil {
+			fatal("creating IRIS client", "instance", name, "error", err)
+		}
+		instances = append(instances, tools.Instance{Name: name, Client: pc})
+	}
 
+	var (
+		opts = mcp.ServerOptions{
+			CompletionHandler: completion.Handler(c),
+			Logger:            logger.With("component", "mcp"),
+		}
+		watcher *resources.Watcher
+	)
+	if cfg.Subscriptions.PollInterval > 0 {
+		watcher = resources.NewWatcher(c, cfg.Subscriptions.PollInterval, cfg.Subscriptions.Max, logger)
+		opts.SubscribeHandler = watcher.Subscribe
+		opts.UnsubscribeHandler = watcher.Unsubscribe
+	}
+	var auditLog *audit.Logger
+	if cfg.Audit.Path != "" {
+		auditLog, err = audit.Open(audit.Config{
+			Path:      cfg.Audit.Path,
+			MaxBytes:  int64(cfg.Audit.MaxBytes),
+			MaxFiles:  cfg.Audit.MaxFiles,
+			HashChain: cfg.Audit.HashChain,
+			Redactor:  redactor,
+		})
+		if err != nil {
+			fatal("opening audit log", "error", err)
+		}
+		defer auditLog.Close()
+	}
 	s := mcp.NewServer(
-		&mcp.Implementation{Name: "dfir-iris-mcp", Version: "1.0.0"},
-		nil,
+		&mcp.Implementation{Name: "dfir-iris-mcp", Version: version},
+		&opts,
 	)
 
-	tools.RegisterAll(s, c)
+	err = tools.RegisterAll(s, c, tools.Options{
+		ReadOnly:  cfg.ReadOnly,
+		Include:   cfg.Tools.Include,
+		Exclude:   cfg.Tools.Exclude,
+		Primary:   cfg.Name(),
+		Instances: instances,
 
-	if err := s.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-		log.Fatalf("server: %v", err)
+		MaxOutputBytes: cfg.Output.Budget(),
+		FetchAllLimit:  cfg.Output.FetchAllLimit,
+		Audit:          auditLog,
+		Redactor:       redactor,
+		Metrics:        metrics,
+		Logger:         logger,
+	})
+	if err != nil {
+		fatal("registering tools", "error", err)
+	}
+	resources.Register(s, c, redactor)
+	prompts.Register(s, c, cfg.Output.Budget(), redactor)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if watcher != nil {
+		go watcher.Run(ctx, s)
+	}
+	if metrics != nil {
+		go func() {
+			if err := serveMetrics(ctx, cfg.Telemetry.MetricsListen, metrics); err != nil {
+				slog.Error("serving metrics", "error", err)
+			}
+		}()
+	}
+	if cfg.Cache.WarmUp && cfg.APIKey != "" {
+		go warmSettings(ctx, cfg.Name(), c)
+		for _, inst := range instances {
+			go warmSettings(ctx, inst.Name, inst.Client)
+		}
+	}
+	if cfg.Listen != "" {
+		err = serveHTTP(ctx, s, cfg)
+	} else {
+		err = s.Run(ctx, &mcp.StdioTransport{})
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fatal("serving MCP", "error", err)
+	}
+}
+
+// fatal logs msg with args as an error and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// warmSettings fills the settings cache of c in the background, so that
+// startup does not wait for IRIS.
+func warmSettings(ctx context.Context, name string, c *client.Client) {
+	if err := c.WarmSettings(ctx); err != nil && ctx.Err() == nil {
+		slog.WarnContext(ctx, "settings warm-up failed", "instance", name, "error", err)
+	}
+}
+
+// newRedactor builds the redactor described by cfg.
+func newRedactor(cfg config.Redaction) (*redact.Redactor, error) {
+	var rules []redact.Rule
+	for _, r := range cfg.Rules {
+		if r.Regex == "" {
+			rule, err := redact.Builtin(r.Name)
+			if err != nil {
+				return nil, err
+			}
+			if r.Replace != "" {
+				rule.Replace = r.Replace
+			}
+			rule.Outputs = r.Outputs
+			rules = append(rules, rule)
+			continue
+		}
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %q: %w", r.Name, err)
+		}
+		replace := r.Replace
+		if replace == "" {
+			replace = redact.Masked
+		}
+		rules = append(rules, redact.Rule{Name: r.Name, Pattern: re, Replace: replace, Outputs: r.Outputs})
+	}
+	return redact.New(cfg.Keys, rules), nil
+}
+
+// verifyAuditLog checks the hash chain of the audit log at p, with its
+// rotated files, and reports the number of records checked.
+func verifyAuditLog(p string) error {
+	var (
+		chain audit.Chain
+		err   error
+		files = []string{"standard input"}
+	)
+	if p == "-" {
+		chain, err = audit.Verify(os.Stdin)
+	} else {
+		chain, files, err = audit.VerifyFiles(p)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	fmt.Printf("%s: %d records in %s, hash chain intact\n", p, chain.Records, strings.Join(files, ", "))
+	if chain.Start != "" {
+		fmt.Printf("The chain continues from record %s of an earlier file that was not checked, such as one rotated out of max_files.\n", chain.Start)
+	}
+	return nil
+}
+
+// newClient builds the IRIS client described by cfg, whose requests and
+// caches are recorded in metrics if non-nil.
+func newClient(cfg *config.Config, metrics *telemetry.Metrics) (*client.Client, error) {
+	transport, err := client.NewTransport(client.TransportConfig{
+		DialTimeout:         cfg.HTTP.DialTimeout,
+		TLSHandshakeTimeout: cfg.HTTP.TLSHandshakeTimeout,
+		ProxyURL:            cfg.HTTP.Proxy,
+		CABundle:            cfg.TLS.CABundle,
+		ClientCert:          cfg.TLS.ClientCert,
+		ClientKey:           cfg.TLS.ClientKey,
+		InsecureSkipVerify:  cfg.TLS.SkipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rules []client.CacheRule
+	for _, r := range cfg.Cache.Rules {
+		if _, err := path.Match(r.Path, ""); err != nil {
+			return nil, fmt.Errorf("invalid cache rule path %q: %w", r.Path, err)
+		}
+		rules = append(rules, client.CacheRule{Pattern: r.Path, TTL: r.TTL})
+	}
+	opts := []client.Option{
+		client.WithLogger(slog.Default().With("instance", cfg.Name())),
+		client.WithTransport(transport),
+		client.WithTimeout(cfg.HTTP.Timeout),
+		client.WithReadOnly(cfg.ReadOnly),
+		client.WithRetry(client.RetryPolicy{
+			MaxRetries: cfg.Retry.MaxRetries,
+			BaseDelay:  cfg.Retry.BaseDelay,
+			MaxDelay:   cfg.Retry.MaxDelay,
+		}),
+		client.WithRateLimit(cfg.Limits.RequestsPerSecond, cfg.Limits.Burst),
+		client.WithMaxInFlight(cfg.Limits.MaxInFlight),
+		client.WithSettingsCache(cfg.Cache.SettingsTTL),
+		client.WithResponseCache(client.ResponseCache{
+			MaxEntries: cfg.Cache.MaxEntries,
+			TTL:        cfg.Cache.TTL,
+			Rules:      rules,
+		}),
+	}
+	if metrics != nil {
+		opts = append(opts, client.WithRequestObserver(metrics.RequestObserver(cfg.Name())))
+	}
+	c := client.New(cfg.BaseURL, cfg.APIKey, opts...)
+	if metrics != nil {
+		metrics.WatchCaches(cfg.Name(), c)
 	}
+	return c, nil
 }
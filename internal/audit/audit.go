@@ -0,0 +1,361 @@
+// Package audit writes an append-only JSON Lines record of every tool call.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"dfir-iris-mcp/internal/redact"
+)
+
+// Config configures the audit log.
+type Config struct {
+	// Path is the file records are appended to, or "-" for stdout.
+	Path string
+	// MaxBytes rotates the file before it grows beyond this size, renaming
+	// it to Path.1 and shifting older files up. Zero disables rotation.
+	MaxBytes int64
+	// MaxFiles is the number of rotated files kept. It must be at least 1
+	// with rotation, so that rotating never discards the records just
+	// written.
+	MaxFiles int
+	// HashChain adds to every record the hash of the previous one and its
+	// own, so that editing, removing or reordering records is detectable.
+	HashChain bool
+	// Redactor masks the arguments, request paths and error messages of
+	// records. If nil, arguments named after redact.DefaultKeys are masked.
+	Redactor *redact.Redactor
+}
+
+// Record is one tool call.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session,omitempty"`
+	// User identifies the owner of the session's own API key, if the
+	// session authenticated with one, by its fingerprint.
+	User     string `json:"user,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Tool     string `json:"tool"`
+	// Arguments are the call arguments, with secrets masked.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Requests are the IRIS requests the call sent, in completion order.
+	Requests   []Request `json:"requests,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	// Error is set if the call failed.
+	Error *Error `json:"error,omitempty"`
+	// ObjectIDs are the IDs found in the result, by field name, such as
+	// {"ioc_id": [12]}.
+	ObjectIDs map[string][]int64 `json:"object_ids,omitempty"`
+
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// Request is an IRIS request sent by a tool call.
+type Request struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Status     int    `json:"status,omitempty"` // zero if IRIS did not answer
+	DurationMS int64  `json:"duration_ms"`
+	Retries    int    `json:"retries,omitempty"`
+}
+
+// Error describes a failed call.
+type Error struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Logger appends records to the audit log. It is safe for concurrent use.
+type Logger struct {
+	cfg Config
+
+	mu   sync.Mutex
+	w    io.Writer
+	f    *os.File // nil for stdout
+	size int64
+	prev string // hash of the last record
+}
+
+// Open opens the audit log described by cfg, appending to an existing file.
+// With HashChain, the chain continues from the last record of the file, or
+// of the most recently rotated file if the current one is empty.
+func Open(cfg Config) (*Logger, error) {
+	if cfg.Redactor == nil {
+		cfg.Redactor = redact.New(redact.DefaultKeys, nil)
+	}
+	if cfg.MaxBytes > 0 && cfg.MaxFiles < 1 {
+		return nil, fmt.Errorf("audit: rotation needs at least one rotated file, got %d", cfg.MaxFiles)
+	}
+	l := &Logger{cfg: cfg}
+	if cfg.Path == "-" {
+		l.w = os.Stdout
+		return l, nil
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	if cfg.HashChain {
+		for _, p := range []string{cfg.Path, cfg.Path + ".1"} {
+			last, err := lastLine(p)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("audit: resuming hash chain: %w", err)
+			}
+			if last != nil {
+				var r Record
+				if err := json.Unmarshal(last, &r); err != nil || r.Hash == "" {
+					return nil, fmt.Errorf("audit: resuming hash chain: last record of %s has no hash", p)
+				}
+				l.prev = r.Hash
+				break
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.cfg.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.f, l.w, l.size = f, f, info.Size()
+	return nil
+}
+
+// Write masks r and appends it to the log, setting its hashes if the log is
+// chained.
+func (l *Logger) Write(r Record) error {
+	rd := l.cfg.Redactor
+	r.Arguments = rd.Arguments(r.Arguments)
+	r.Requests = slices.Clone(r.Requests)
+	for i := range r.Requests {
+		r.Requests[i].Path = rd.String(r.Requests[i].Path)
+	}
+	if r.Error != nil {
+		r.Error = &Error{Kind: r.Error.Kind, Message: rd.String(r.Error.Message)}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r.PrevHash, r.Hash = "", ""
+	if l.cfg.HashChain {
+		r.PrevHash = l.prev
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	if l.cfg.HashChain {
+		r.Hash = hash(line)
+		line = append(line[:len(line)-1], `,"hash":"`+r.Hash+`"}`...)
+	}
+	line = append(line, '\n')
+
+	// A failed rotation leaves the current file open, so the record is
+	// appended to it rather than lost; the error is still reported.
+	var rotateErr error
+	if l.f != nil && l.cfg.MaxBytes > 0 && l.size > 0 && l.size+int64(len(line)) > l.cfg.MaxBytes {
+		rotateErr = l.rotate()
+	}
+	if l.w == nil {
+		// Reopening after rotation failed; try again.
+		if err := l.open(); err != nil {
+			return errors.Join(rotateErr, err)
+		}
+	}
+	n, err := l.w.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return errors.Join(rotateErr, fmt.Errorf("audit: %w", err))
+	}
+	l.prev = r.Hash
+	if rotateErr != nil {
+		return fmt.Errorf("%w (the record was appended to %s)", rotateErr, l.cfg.Path)
+	}
+	return nil
+}
+
+// rotate renames the log to Path.1, after shifting the rotated files up and
+// dropping those beyond MaxFiles, and opens a new file. Whatever fails, the
+// log is open again afterwards, on the new file or the old one, unless it
+// cannot be opened at all; then l.w is nil.
+func (l *Logger) rotate() (err error) {
+	defer func() {
+		if oerr := l.open(); oerr != nil {
+			l.f, l.w = nil, nil
+			err = errors.Join(err, oerr)
+		}
+	}()
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	p := l.cfg.Path
+	_ = os.Remove(fmt.Sprintf("%s.%d", p, l.cfg.MaxFiles))
+	for i := l.cfg.MaxFiles - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", p, i), fmt.Sprintf("%s.%d", p, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("audit: rotating: %w", err)
+		}
+	}
+	if err := os.Rename(p, p+".1"); err != nil {
+		return fmt.Errorf("audit: rotating: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// Chain describes a hash chain checked by Verify.
+type Chain struct {
+	// Records is the number of records checked.
+	Records int
+	// Start is the previous hash of the first record: empty if the records
+	// start the chain, or else the hash of the last record of an earlier
+	// file, such as one rotated out of MaxFiles.
+	Start string
+	// End is the hash of the last record.
+	End string
+}
+
+// Verify checks the hash chain of the records read from r, which may be the
+// concatenation of rotated files from oldest to newest. Every record must
+// carry the hash of the one before it; the first may continue a chain from
+// an earlier file, which Chain.Start then identifies.
+func Verify(r io.Reader) (Chain, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	var c Chain
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		c.Records++
+		n := c.Records
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return c, fmt.Errorf("record %d: %w", n, err)
+		}
+		suffix := `,"hash":"` + rec.Hash + `"}`
+		if rec.Hash == "" || !bytes.HasSuffix(line, []byte(suffix)) {
+			return c, fmt.Errorf("record %d: missing hash", n)
+		}
+		body := append(bytes.Clone(line[:len(line)-len(suffix)]), '}')
+		if hash(body) != rec.Hash {
+			return c, fmt.Errorf("record %d: hash mismatch: the record was modified", n)
+		}
+		if n == 1 {
+			c.Start = rec.PrevHash
+		} else if rec.PrevHash != c.End {
+			return c, fmt.Errorf("record %d: previous hash mismatch: a record was removed, inserted or reordered", n)
+		}
+		c.End = rec.Hash
+	}
+	return c, sc.Err()
+}
+
+// VerifyFiles checks the hash chain across the audit log at path and its
+// rotated files, from the oldest to path itself. Each file must continue
+// the chain where the previous one ended.
+func VerifyFiles(path string) (Chain, []string, error) {
+	var files []string
+	for i := 1; ; i++ {
+		p := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(p); err != nil {
+			break
+		}
+		files = append(files, p)
+	}
+	slices.Reverse(files)
+	files = append(files, path)
+
+	var (
+		total Chain
+		prev  string // the last file with records
+	)
+	for _, p := range files {
+		f, err := os.Open(p)
+		if err != nil {
+			return total, files, err
+		}
+		c, err := Verify(f)
+		f.Close()
+		if err != nil {
+			return total, files, fmt.Errorf("%s: %w", p, err)
+		}
+		if c.Records == 0 {
+			continue
+		}
+		if total.Records == 0 {
+			total.Start = c.Start
+		} else if c.Start != total.End {
+			return total, files, fmt.Errorf("%s: does not continue the chain of %s: records were removed, or a file was replaced", p, prev)
+		}
+		total.Records += c.Records
+		total.End = c.End
+		prev = p
+	}
+	return total, files, nil
+}
+
+func hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastLine returns the last non-empty line of the file at p, or nil if it
+// has none.
+func lastLine(p string) ([]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	end := info.Size()
+	var tail []byte
+	for chunk := int64(4096); ; chunk *= 2 {
+		start := max(end-chunk, 0)
+		tail = make([]byte, end-start)
+		if _, err := f.ReadAt(tail, start); err != nil && err != io.EOF {
+			return nil, err
+		}
+		trimmed := bytes.TrimRight(tail, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+		if start == 0 {
+			if len(trimmed) == 0 {
+				return nil, nil
+			}
+			return trimmed, nil
+		}
+	}
+}
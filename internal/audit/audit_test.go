@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog writes n chained records to a new log in a temporary directory
+// and returns its path.
+func writeLog(t *testing.T, cfg Config, n int) string {
+	t.Helper()
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join(t.TempDir(), "audit.jsonl")
+	}
+	cfg.HashChain = true
+	l, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		if err := l.Write(Record{Tool: "dfir_iris_cases_list", Arguments: map[string]any{"page": i}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfg.Path
+}
+
+func readLines(t *testing.T, p string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.SplitAfter(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	lines := readLines(t, writeLog(t, Config{}, 3))
+	join := func(ls ...[]byte) string {
+		var sb strings.Builder
+		for _, l := range ls {
+			sb.WriteString(strings.TrimSuffix(string(l), "\n") + "\n")
+		}
+		return sb.String()
+	}
+
+	for _, tt := range []struct {
+		name    string
+		log     string
+		wantErr string
+	}{
+		{"intact", join(lines...), ""},
+		{"modified", join(lines[0], bytes.Replace(lines[1], []byte(`"page":1`), []byte(`"page":7`), 1), lines[2]), "record 2: hash mismatch"},
+		{"removed", join(lines[0], lines[2]), "record 2: previous hash mismatch"},
+		{"reordered", join(lines[0], lines[2], lines[1]), "record 2: previous hash mismatch"},
+		{"inserted", join(lines[0], lines[1], lines[1], lines[2]), "record 3: previous hash mismatch"},
+		{"hash removed", join(lines[0], bytes.Replace(lines[1], []byte(`,"hash":`), []byte(`,"h":`), 1)), "record 2: missing hash"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Verify(strings.NewReader(tt.log))
+			if tt.wantErr == "" {
+				if err != nil || c.Records != 3 || c.Start != "" {
+					t.Fatalf("Verify() = %+v, %v; want 3 records from the start of the chain", c, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The first record of a later file continues the chain of the earlier
+	// one, which Start reports.
+	c, err := Verify(bytes.NewReader(lines[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, err := Verify(strings.NewReader(join(lines[:2]...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Start == "" || c.Start != prev.End {
+		t.Errorf("Start = %q, want the hash %q of the record before", c.Start, prev.End)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "audit.jsonl")
+	// Every record is over 100 bytes, so each write rotates.
+	cfg := Config{Path: p, MaxBytes: 100, MaxFiles: 2}
+	writeLog(t, cfg, 3)
+	// Reopening resumes the chain from the last rotated file.
+	writeLog(t, cfg, 2)
+
+	for _, name := range []string{"audit.jsonl", "audit.jsonl.1", "audit.jsonl.2"} {
+		if n := len(readLines(t, filepath.Join(dir, name))); n != 1 {
+			t.Errorf("%s has %d records, want 1", name, n)
+		}
+	}
+	if _, err := os.Stat(p + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 was kept beyond MaxFiles: %v", p, err)
+	}
+
+	c, files, err := VerifyFiles(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{p + ".2", p + ".1", p}; strings.Join(files, " ") != strings.Join(want, " ") {
+		t.Errorf("verified %q, want %q", files, want)
+	}
+	if c.Records != 3 || c.Start == "" {
+		t.Errorf("VerifyFiles() = %+v, want 3 records continuing a rotated-out file", c)
+	}
+
+	// Removing a rotated file breaks the chain between files.
+	if err := os.Remove(p + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(p+".2", p+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyFiles(p); err == nil || !strings.Contains(err.Error(), "does not continue the chain") {
+		t.Errorf("VerifyFiles() error = %v, want a broken chain between files", err)
+	}
+}
+
+func TestRotationFailureKeepsRecords(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "audit.jsonl")
+	l, err := Open(Config{Path: p, MaxBytes: 100, MaxFiles: 1, HashChain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Write(Record{Tool: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A non-empty directory in the way of audit.jsonl.1 makes the rename
+	// fail.
+	if err := os.MkdirAll(filepath.Join(p+".1", "x"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range []string{"second", "third"} {
+		if err := l.Write(Record{Tool: tool}); err == nil || !strings.Contains(err.Error(), "appended to") {
+			t.Fatalf("Write(%s) error = %v, want a rotation error", tool, err)
+		}
+	}
+	if n := len(readLines(t, p)); n != 3 {
+		t.Fatalf("%s has %d records, want 3", p, n)
+	}
+
+	if err := os.RemoveAll(p + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Write(Record{Tool: "fourth"}); err != nil {
+		t.Fatalf("Write after the rotation was fixed: %v", err)
+	}
+	c, _, err := VerifyFiles(p)
+	if err != nil || c.Records != 4 || c.Start != "" {
+		t.Errorf("VerifyFiles() = %+v, %v; want all 4 records", c, err)
+	}
+}
+
+func TestOpenRejectsRotationWithoutFiles(t *testing.T) {
+	_, err := Open(Config{Path: filepath.Join(t.TempDir(), "audit.jsonl"), MaxBytes: 100})
+	if err == nil {
+		t.Fatal("Open() accepted rotation that would discard the log")
+	}
+}
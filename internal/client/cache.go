@@ -0,0 +1,204 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheRule sets the TTL of the GET responses whose path matches Pattern,
+// a path.Match pattern such as "/manage/*/list". A zero TTL disables caching
+// for those paths.
+type CacheRule struct {
+	Pattern string
+	TTL     time.Duration
+}
+
+// ResponseCache configures the GET response cache. The first rule matching
+// a path applies, and TTL otherwise.
+type ResponseCache struct {
+	MaxEntries int
+	TTL        time.Duration
+	Rules      []CacheRule
+}
+
+// relatedFamilies lists the families whose responses also change when a
+// request to a family succeeds, beyond the family itself.
+var relatedFamilies = map[string][]string{
+	"/case/summary": {"/manage/cases"},
+	"/alerts":       {"/manage/cases"}, // escalation and merges create or change cases
+}
+
+// WithResponseCache makes the client cache GET responses in memory, keyed
+// by path, query and credential, and evicting the least recently used entry
+// beyond cfg.MaxEntries. A successful POST drops the cached responses of
+// the same resource family, within the same case if it names one. A
+// non-positive MaxEntries disables the cache.
+func WithResponseCache(cfg ResponseCache) Option {
+	return func(c *Client) {
+		if cfg.MaxEntries <= 0 {
+			c.responses = nil
+			return
+		}
+		c.responses = &responseCache{
+			cfg:     cfg,
+			lru:     list.New(),
+			entries: make(map[string]*list.Element),
+		}
+	}
+}
+
+type noCacheContextKey struct{}
+
+// WithoutCache returns a context whose GET requests bypass the response
+// cache, for callers that look for changes.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheContextKey{}, true)
+}
+
+type responseCache struct {
+	cfg ResponseCache
+
+	mu           sync.Mutex
+	lru          *list.List // of *cachedResponse, most recently used first
+	entries      map[string]*list.Element
+	hits, misses int64
+}
+
+type cachedResponse struct {
+	key     string
+	family  string
+	cid     string
+	data    json.RawMessage
+	expires time.Time
+}
+
+// ttl returns how long the responses of p are cached.
+func (r *responseCache) ttl(p string) time.Duration {
+	for _, rule := range r.cfg.Rules {
+		if ok, _ := path.Match(rule.Pattern, p); ok {
+			return rule.TTL
+		}
+	}
+	return r.cfg.TTL
+}
+
+func (r *responseCache) get(key string) (json.RawMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	el, ok := r.entries[key]
+	if !ok {
+		r.misses++
+		return nil, false
+	}
+	e := el.Value.(*cachedResponse)
+	if time.Now().After(e.expires) {
+		r.lru.Remove(el)
+		delete(r.entries, key)
+		r.misses++
+		return nil, false
+	}
+	r.lru.MoveToFront(el)
+	r.hits++
+	return e.data, true
+}
+
+func (r *responseCache) put(e *cachedResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.entries[e.key]; ok {
+		el.Value = e
+		r.lru.MoveToFront(el)
+		return
+	}
+	r.entries[e.key] = r.lru.PushFront(e)
+	for r.lru.Len() > r.cfg.MaxEntries {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// invalidate drops the responses that a successful POST to p with query
+// may have changed: those of its family and related families, restricted
+// to its case if it has one.
+func (r *responseCache) invalidate(p string, query map[string]string) {
+	families := append([]string{family(p)}, relatedFamilies[family(p)]...)
+	cid := query["cid"]
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for el := r.lru.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*cachedResponse)
+		for _, f := range families {
+			if e.family == f && (cid == "" || e.cid == "" || e.cid == cid) {
+				r.lru.Remove(el)
+				delete(r.entries, e.key)
+				break
+			}
+		}
+		el = next
+	}
+}
+
+// ResponseCacheStats returns the statistics of the response cache, which are
+// zero without one.
+func (c *Client) ResponseCacheStats() CacheStats {
+	r := c.responses
+	if r == nil {
+		return CacheStats{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return newCacheStats(r.lru.Len(), r.hits, r.misses)
+}
+
+// getCached serves a GET from the response cache, or sends it and caches a
+// successful response.
+func (c *Client) getCached(ctx context.Context, p string, query map[string]string) (json.RawMessage, error) {
+	ttl := c.responses.ttl(p)
+	if ttl <= 0 || ctx.Value(noCacheContextKey{}) != nil {
+		return c.do(ctx, http.MethodGet, p, query, nil)
+	}
+	apiKey, err := c.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := make(url.Values, len(query))
+	for k, v := range query {
+		q.Set(k, v)
+	}
+	key := credentialID(apiKey) + " " + p + "?" + q.Encode()
+	if data, ok := c.responses.get(key); ok {
+		return data, nil
+	}
+	data, err := c.do(ctx, http.MethodGet, p, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.responses.put(&cachedResponse{
+		key:     key,
+		family:  family(p),
+		cid:     query["cid"],
+		data:    data,
+		expires: time.Now().Add(ttl),
+	})
+	return data, nil
+}
+
+// family returns the resource family of an IRIS path: its first two
+// segments under /case and /manage, such as /case/ioc, and its first
+// segment otherwise, such as /alerts.
+func family(p string) string {
+	segs := strings.SplitN(strings.TrimPrefix(p, "/"), "/", 3)
+	if len(segs) > 1 && (segs[0] == "case" || segs[0] == "manage") {
+		return "/" + segs[0] + "/" + segs[1]
+	}
+	return "/" + segs[0]
+}
@@ -3,31 +3,55 @@ package client
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/time/rate"
 )
 
 type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	readOnly   bool
+	retry      RetryPolicy
+	limiter    *rate.Limiter
+	inFlight   chan struct{} // semaphore, nil if unlimited
+	settings   *settingsCache
+	responses  *responseCache
+	observe    func(Request) // nil if unobserved
+	log        *slog.Logger  // nil for the default logger
 }
 
-func New(baseURL, apiKey string) *Client {
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	if os.Getenv("DFIR_IRIS_TLS_SKIP_VERIFY") != "" {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-	return &Client{
+// Option configures optional Client behaviour.
+type Option func(*Client)
+
+// WithReadOnly makes the client refuse every POST except searches.
+func WithReadOnly(readOnly bool) Option {
+	return func(c *Client) { c.readOnly = readOnly }
+}
+
+// ErrReadOnly is returned for mutating requests made by a read-only client.
+var ErrReadOnly = errors.New("DFIR-IRIS client is read-only")
+
+func New(baseURL, apiKey string, opts ...Option) *Client {
+	c := &Client{
 		baseURL:    baseURL,
 		apiKey:     apiKey,
-		httpClient: &http.Client{Transport: transport},
+		httpClient: &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()},
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 type envelope struct {
@@ -40,21 +64,94 @@ type APIError struct {
 	StatusCode int
 	Status     string
 	Message    string
+	// Retries is the number of retries made before giving up.
+	Retries int
+	// Kind classifies the error from the status code and message.
+	Kind ErrorKind
+	// Fields holds the messages per argument of a validation error, from
+	// the data of the IRIS envelope.
+	Fields map[string][]string
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("DFIR-IRIS API error (HTTP %d): %s - %s", e.StatusCode, e.Status, e.Message)
+	msg := fmt.Sprintf("DFIR-IRIS API error (HTTP %d): %s - %s", e.StatusCode, e.Status, e.Message)
+	if e.Retries > 0 {
+		msg += fmt.Sprintf(" (after %d retries)", e.Retries)
+	}
+	return msg
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context whose requests authenticate to DFIR-IRIS with
+// key instead of the client's own key, so that actions are attributed to the
+// user who owns it.
+func WithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+func (c *Client) apiKeyFor(ctx context.Context) (string, error) {
+	if k, ok := ctx.Value(apiKeyContextKey{}).(string); ok && k != "" {
+		return k, nil
+	}
+	if c.apiKey == "" {
+		return "", ErrNoAPIKey
+	}
+	return c.apiKey, nil
+}
+
+// Credential identifies the API key that requests with ctx authenticate
+// with, so that callers can tell users apart without holding their keys.
+func (c *Client) Credential(ctx context.Context) (string, error) {
+	apiKey, err := c.apiKeyFor(ctx)
+	if err != nil {
+		return "", err
+	}
+	return credentialID(apiKey), nil
+}
+
+// CaseQuery returns the query selecting the case that case-scoped endpoints
+// operate on. Callers may add further parameters to the returned map.
+func CaseQuery(caseID int) map[string]string {
+	return map[string]string{"cid": strconv.Itoa(caseID)}
 }
 
 func (c *Client) Get(ctx context.Context, path string, query map[string]string) (json.RawMessage, error) {
+	c = c.route(ctx)
+	if c.settings != nil && len(query) == 0 && isSettingsPath(path) {
+		return c.getSetting(ctx, path)
+	}
+	if c.responses != nil {
+		return c.getCached(ctx, path, query)
+	}
 	return c.do(ctx, http.MethodGet, path, query, nil)
 }
 
 func (c *Client) Post(ctx context.Context, path string, query map[string]string, body interface{}) (json.RawMessage, error) {
-	return c.do(ctx, http.MethodPost, path, query, body)
+	// Tools are already filtered in read-only mode; this guards against a
+	// mutating call slipping through a new or misclassified tool.
+	c = c.route(ctx)
+	if c.readOnly && !strings.HasSuffix(path, "/search") {
+		return nil, fmt.Errorf("POST %s: %w", path, ErrReadOnly)
+	}
+	data, err := c.do(ctx, http.MethodPost, path, query, body)
+	if err == nil && !strings.HasSuffix(path, "/search") {
+		if c.responses != nil {
+			c.responses.invalidate(path, query)
+		}
+		if c.settings != nil {
+			c.settings.invalidate(path)
+		}
+	}
+	return data, err
 }
 
-func (c *Client) do(ctx context.Context, method, path string, query map[string]string, body interface{}) (json.RawMessage, error) {
+func (c *Client) do(ctx context.Context, method, path string, query map[string]string, body interface{}) (_ json.RawMessage, err error) {
+	apiKey, err := c.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	u, err := url.Parse(c.baseURL + path)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
@@ -68,39 +165,85 @@ func (c *Client) do(ctx context.Context, method, path string, query map[string]s
 		u.RawQuery = q.Encode()
 	}
 
-	var bodyReader io.Reader
+	var reqBody []byte
 	if body != nil {
-		b, err := json.Marshal(body)
+		reqBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("marshaling request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(b)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
-	}
-	defer resp.Body.Close()
+	var (
+		resp     *http.Response
+		respBody []byte
+		retries  int
+	)
+	ctx, done := c.startRequest(ctx, method, path, u.RawQuery)
+	defer func() {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		done(status, retries, err)
+	}()
+	for ; ; retries++ {
+		var bodyReader io.Reader
+		if reqBody != nil {
+			bodyReader = bytes.NewReader(reqBody)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		if reqBody != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		release, err := c.acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+		resp, err = c.httpClient.Do(req)
+		if err == nil {
+			respBody, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				err = fmt.Errorf("reading response: %w", err)
+			}
+		}
+		release()
+		delay, retry := c.retryDelay(ctx, method, path, retries, resp, err)
+		if !retry {
+			if err != nil {
+				if retries > 0 {
+					return nil, fmt.Errorf("executing request (gave up after %d retries): %w", retries, err)
+				}
+				return nil, fmt.Errorf("executing request: %w", err)
+			}
+			break
+		}
+		attrs := []any{"method", method, "path", path, "attempt", retries + 1, "delay", delay}
+		if err != nil {
+			attrs = append(attrs, "error", err.Error())
+		} else {
+			attrs = append(attrs, "status", resp.StatusCode)
+		}
+		c.logger(ctx).WarnContext(ctx, "retrying IRIS request", attrs...)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("waiting to retry: %w", err)
+		}
 	}
 
 	var env envelope
 	if err := json.Unmarshal(respBody, &env); err != nil {
 		if resp.StatusCode >= 400 {
-			return nil, &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+			return nil, &APIError{
+				StatusCode: resp.StatusCode,
+				Message:    string(respBody),
+				Retries:    retries,
+				Kind:       classify(resp.StatusCode, string(respBody), nil),
+			}
 		}
 		return respBody, nil
 	}
@@ -110,10 +253,14 @@ func (c *Client) do(ctx context.Context, method, path string, query map[string]s
 		if len(env.Data) > 0 && string(env.Data) != "null" {
 			msg = msg + " - " + string(env.Data)
 		}
+		fields := parseFields(env.Data)
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Status:     env.Status,
 			Message:    msg,
+			Retries:    retries,
+			Kind:       classify(resp.StatusCode, env.Message, fields),
+			Fields:     fields,
 		}
 	}
 
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+)
+
+// ErrorKind classifies why a request failed, so that callers can react
+// without parsing messages.
+type ErrorKind string
+
+const (
+	KindAuth       ErrorKind = "auth"              // the API key is missing or rejected
+	KindPermission ErrorKind = "permission_denied" // the key's user may not access the case or action
+	KindNotFound   ErrorKind = "not_found"
+	KindValidation ErrorKind = "validation" // IRIS rejected the arguments
+	KindConflict   ErrorKind = "conflict"
+	KindRateLimit  ErrorKind = "rate_limited"
+	KindServer     ErrorKind = "server_error"
+	KindNetwork    ErrorKind = "network"
+	KindTimeout    ErrorKind = "timeout"
+	KindReadOnly   ErrorKind = "read_only"
+	KindUnknown    ErrorKind = "unknown"
+)
+
+// Retryable reports whether the same request may succeed later.
+func (k ErrorKind) Retryable() bool {
+	switch k {
+	case KindRateLimit, KindServer, KindNetwork, KindTimeout:
+		return true
+	}
+	return false
+}
+
+// ErrNoAPIKey is returned when a request has no API key to authenticate
+// with.
+var ErrNoAPIKey = errors.New("no DFIR-IRIS API key: send one as a Bearer token in the Authorization header")
+
+// ArgumentError is an argument found invalid before any request is sent,
+// such as a name that matches no IOC type.
+type ArgumentError struct {
+	Field   string
+	Message string
+}
+
+func (e *ArgumentError) Error() string { return e.Message }
+
+// Kind classifies err, which is typically returned by Get or Post.
+func Kind(err error) ErrorKind {
+	var apiErr *APIError
+	var argErr *ArgumentError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &apiErr):
+		return apiErr.Kind
+	case errors.As(err, &argErr):
+		return KindValidation
+	case errors.Is(err, ErrReadOnly):
+		return KindReadOnly
+	case errors.Is(err, ErrNoAPIKey):
+		return KindAuth
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return KindTimeout
+		}
+		return KindNetwork
+	}
+	return KindUnknown
+}
+
+// Fields returns the messages per argument that explain err, if it is a
+// validation error.
+func Fields(err error) map[string][]string {
+	var apiErr *APIError
+	var argErr *ArgumentError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Fields
+	case errors.As(err, &argErr):
+		return map[string][]string{argErr.Field: {argErr.Message}}
+	}
+	return nil
+}
+
+// notFoundMessage matches the messages IRIS gives with a 400 when an ID
+// does not exist, such as "Invalid IOC ID for this case".
+var notFoundMessage = regexp.MustCompile(`(?i)not found|invalid [a-z ]*\bid\b`)
+
+// classify returns the kind of an IRIS error response.
+func classify(status int, message string, fields map[string][]string) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized:
+		return KindAuth
+	case status == http.StatusForbidden:
+		return KindPermission
+	case status == http.StatusNotFound:
+		return KindNotFound
+	case status == http.StatusConflict:
+		return KindConflict
+	case status == http.StatusTooManyRequests:
+		return KindRateLimit
+	case status >= 500:
+		return KindServer
+	case len(fields) > 0:
+		return KindValidation
+	case notFoundMessage.MatchString(message):
+		return KindNotFound
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return KindValidation
+	}
+	return KindUnknown
+}
+
+// parseFields extracts per-field messages from the data of an IRIS error
+// envelope, which maps field names to a message or a list of them.
+func parseFields(data json.RawMessage) map[string][]string {
+	var raw map[string]any
+	if json.Unmarshal(data, &raw) != nil {
+		return nil
+	}
+	fields := make(map[string][]string)
+	for name, v := range raw {
+		switch v := v.(type) {
+		case string:
+			fields[name] = []string{v}
+		case []any:
+			for _, m := range v {
+				fields[name] = append(fields[name], fmt.Sprint(m))
+			}
+		case map[string]any:
+			// Nested schemas, e.g. custom attributes.
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fields[name] = append(fields[name], fmt.Sprintf("%s: %v", k, v[k]))
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
@@ -0,0 +1,26 @@
+package client
+
+import "context"
+
+type instanceContextKey struct{}
+
+// WithInstance returns a context whose requests are sent by target, whichever
+// Client they are made through. Tools hold a single Client; this lets one
+// call be routed to another IRIS deployment.
+func WithInstance(ctx context.Context, target *Client) context.Context {
+	return context.WithValue(ctx, instanceContextKey{}, target)
+}
+
+// route returns the Client that should send requests made with ctx.
+func (c *Client) route(ctx context.Context) *Client {
+	if t, ok := ctx.Value(instanceContextKey{}).(*Client); ok && t != nil {
+		return t
+	}
+	return c
+}
+
+// BaseURL returns the IRIS URL the client talks to.
+func (c *Client) BaseURL() string { return c.baseURL }
+
+// ReadOnly reports whether the client refuses mutating requests.
+func (c *Client) ReadOnly() bool { return c.readOnly }
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit limits requests to IRIS to perSecond on average, allowing
+// bursts of up to burst requests. A non-positive perSecond disables the
+// limit.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Client) {
+		if perSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(perSecond), max(burst, 1))
+	}
+}
+
+// WithMaxInFlight caps the number of concurrent requests to IRIS.
+// A non-positive n disables the cap.
+func WithMaxInFlight(n int) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			c.inFlight = nil
+			return
+		}
+		c.inFlight = make(chan struct{}, n)
+	}
+}
+
+// acquire waits until the rate limit and the in-flight cap admit another
+// request, or ctx is done. The returned func must be called once the request
+// has completed. The limits are per Client and so shared by every tool call
+// and session using it.
+func (c *Client) acquire(ctx context.Context) (release func(), err error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.inFlight <- struct{}{}:
+		return func() { <-c.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"dfir-iris-mcp/internal/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Request describes a request sent to IRIS, including its retries.
+type Request struct {
+	Method string
+	// Path includes the query string, e.g. "/case/ioc/list?cid=3".
+	Path string
+	// Route is Path without its query and with numeric segments replaced
+	// by {id}, e.g. "/case/ioc/{id}", for use as a metric label.
+	Route string
+	// Status is the HTTP status of the last attempt, or 0 if no response
+	// was received.
+	Status   int
+	Duration time.Duration
+	Retries  int
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// WithLogger sets the logger of requests whose context carries none, see
+// logging.WithLogger. The default logger applies otherwise.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) { c.log = l }
+}
+
+func (c *Client) logger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, c.log)
+}
+
+// WithRequestObserver makes the client report every request it sends to
+// observe once it completes, e.g. to export metrics.
+func WithRequestObserver(observe func(Request)) Option {
+	return func(c *Client) { c.observe = observe }
+}
+
+type observerContextKey struct{}
+
+// WithObserver returns a context whose requests to IRIS are reported to
+// observe once they complete. Requests served from a cache are not sent and
+// so not reported.
+func WithObserver(ctx context.Context, observe func(Request)) context.Context {
+	return context.WithValue(ctx, observerContextKey{}, observe)
+}
+
+func observerFrom(ctx context.Context) func(Request) {
+	observe, _ := ctx.Value(observerContextKey{}).(func(Request))
+	return observe
+}
+
+var tracer = otel.Tracer("dfir-iris-mcp/internal/client")
+
+// idSegment matches the numeric segments of IRIS paths.
+var idSegment = regexp.MustCompile(`/\d+(/|$)`)
+
+// pathRoute returns path with its numeric segments replaced by {id}.
+func pathRoute(path string) string {
+	// Replace twice, as adjacent IDs share the slash between them.
+	for range 2 {
+		path = idSegment.ReplaceAllString(path, "/{id}$1")
+	}
+	return path
+}
+
+// startRequest starts the span of a request and returns its context along
+// with the function ending the span and reporting the request to the
+// observers of the client and of ctx.
+func (c *Client) startRequest(ctx context.Context, method, path, rawQuery string) (context.Context, func(status, retries int, err error)) {
+	start := time.Now()
+	r := Request{Method: method, Path: path, Route: pathRoute(path)}
+	if rawQuery != "" {
+		r.Path += "?" + rawQuery
+	}
+	observers := []func(Request){c.observe, observerFrom(ctx)}
+	log := c.logger(ctx)
+	ctx, span := tracer.Start(ctx, method+" "+r.Route,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("http.route", r.Route),
+			attribute.String("url.path", path),
+		))
+	return ctx, func(status, retries int, err error) {
+		r.Status, r.Retries, r.Err = status, retries, err
+		r.Duration = time.Since(start)
+		if status != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+		}
+		if retries > 0 {
+			span.SetAttributes(attribute.Int("http.request.resend_count", retries))
+		}
+		if err != nil {
+			// The kind rather than the message, which may quote secrets.
+			span.SetAttributes(attribute.String("error.type", string(Kind(err))))
+			span.SetStatus(codes.Error, string(Kind(err)))
+		}
+		span.End()
+		attrs := []any{"method", method, "path", r.Path, "status", status, "duration", r.Duration}
+		if retries > 0 {
+			attrs = append(attrs, "retries", retries)
+		}
+		if err != nil {
+			attrs = append(attrs, "error_kind", Kind(err))
+		}
+		log.DebugContext(ctx, "IRIS request", attrs...)
+		for _, observe := range observers {
+			if observe != nil {
+				observe(r)
+			}
+		}
+	}
+}
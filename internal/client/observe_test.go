@@ -0,0 +1,25 @@
+package client
+
+import "testing"
+
+func TestPathRoute(t *testing.T) {
+	for _, tt := range []struct {
+		path, want string
+	}{
+		{"/manage/cases/list", "/manage/cases/list"},
+		{"/manage/cases/3", "/manage/cases/{id}"},
+		{"/case/ioc/update/42", "/case/ioc/update/{id}"},
+		{"/case/ioc/7/comments/9/edit", "/case/ioc/{id}/comments/{id}/edit"},
+		// Adjacent IDs share the slash between them.
+		{"/case/notes/12/34", "/case/notes/{id}/{id}"},
+		{"/a/1/2/3", "/a/{id}/{id}/{id}"},
+		{"/datastore/file/info/5/", "/datastore/file/info/{id}/"},
+		// Segments that merely contain digits are kept.
+		{"/case/v2/assets", "/case/v2/assets"},
+		{"/alerts/7a", "/alerts/7a"},
+	} {
+		if got := pathRoute(tt.path); got != tt.want {
+			t.Errorf("pathRoute(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Idempotent requests
+// (GETs and searches) are retried on network errors and on 429, 502, 503 and
+// 504 responses. Other POSTs are only retried when IRIS cannot have acted on
+// them: the connection was never established, or the request was rate
+// limited.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	// Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry. It doubles on each
+	// further retry, and the actual delay is drawn uniformly below it.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff. A Retry-After longer than MaxDelay
+	// ends retrying instead of blocking the tool call.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetry is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// WithRetry sets the retry policy.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// retryDelay reports whether the outcome of attempt (0-based) should be
+// retried and how long to wait first.
+func (c *Client) retryDelay(ctx context.Context, method, path string, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= c.retry.MaxRetries || ctx.Err() != nil {
+		return 0, false
+	}
+	idempotent := method == http.MethodGet || strings.HasSuffix(path, "/search")
+
+	switch {
+	case err != nil:
+		// Includes per-attempt timeouts; the caller's own cancellation was
+		// ruled out above.
+		if !idempotent && !notSent(err) {
+			return 0, false
+		}
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		if !idempotent {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return d, d <= c.retry.MaxDelay
+		}
+	}
+	backoff := c.retry.BaseDelay << attempt
+	if backoff <= 0 || backoff > c.retry.MaxDelay {
+		backoff = c.retry.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+	return rand.N(backoff), true
+}
+
+// notSent reports whether err means the request never reached IRIS.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
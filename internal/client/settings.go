@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SettingsPaths are the IRIS endpoints listing settings: asset and IOC
+// types, task and analysis statuses, case states, templates and
+// classifications, evidence types and event categories. They rarely change,
+// so a client with a settings cache serves them from memory.
+var SettingsPaths = []string{
+	"/manage/asset-type/list",
+	"/manage/ioc-types/list",
+	"/manage/task-status/list",
+	"/manage/analysis-status/list",
+	"/manage/case-states/list",
+	"/manage/case-templates/list",
+	"/manage/case-classifications/list",
+	"/manage/evidence-types/list",
+	"/manage/event-categories/list",
+}
+
+// WithSettingsCache makes the client cache the responses of SettingsPaths
+// for ttl. A non-positive ttl disables the cache.
+func WithSettingsCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			c.settings = nil
+			return
+		}
+		c.settings = &settingsCache{ttl: ttl, entries: make(map[string]settingsEntry)}
+	}
+}
+
+// settingsCache holds settings responses by credential and path, so that a
+// session never sees settings fetched with another session's key.
+type settingsCache struct {
+	ttl time.Duration
+
+	mu           sync.Mutex
+	entries      map[string]settingsEntry
+	hits, misses int64
+}
+
+type settingsEntry struct {
+	data    json.RawMessage
+	expires time.Time
+}
+
+// CacheStats describes the use of a cache.
+type CacheStats struct {
+	Entries int     `json:"entries"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+func isSettingsPath(path string) bool {
+	for _, p := range SettingsPaths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// getSetting returns the settings at path, from the cache when fresh.
+func (c *Client) getSetting(ctx context.Context, path string) (json.RawMessage, error) {
+	apiKey, err := c.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := credentialID(apiKey) + " " + path
+	s := c.settings
+	s.mu.Lock()
+	e, ok := s.entries[key]
+	if ok && time.Now().Before(e.expires) {
+		s.hits++
+		s.mu.Unlock()
+		return e.data, nil
+	}
+	s.misses++
+	s.mu.Unlock()
+
+	data, err := c.do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.entries[key] = settingsEntry{data: data, expires: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	return data, nil
+}
+
+// invalidate drops the cached settings of the family of p, to which a
+// request, such as adding an IOC type, succeeded.
+func (s *settingsCache) invalidate(p string) {
+	f := family(p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.entries {
+		if _, settingsPath, _ := strings.Cut(key, " "); family(settingsPath) == f {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// WarmSettings loads every settings list into the cache, so that the first
+// tool calls do not wait for IRIS. It does nothing without a settings cache.
+func (c *Client) WarmSettings(ctx context.Context) error {
+	c = c.route(ctx)
+	if c.settings == nil {
+		return nil
+	}
+	var errs []error
+	for _, path := range SettingsPaths {
+		if _, err := c.getSetting(ctx, path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RefreshSettings drops every cached settings list, for all credentials,
+// and loads them again with the credentials of ctx. It returns the
+// statistics of the cache before the refresh.
+func (c *Client) RefreshSettings(ctx context.Context) (CacheStats, error) {
+	c = c.route(ctx)
+	if c.settings == nil {
+		return CacheStats{}, errors.New("the settings cache is disabled")
+	}
+	stats := c.SettingsStats()
+	c.settings.mu.Lock()
+	clear(c.settings.entries)
+	c.settings.mu.Unlock()
+	return stats, c.WarmSettings(ctx)
+}
+
+// SettingsStats returns the statistics of the settings cache, which are zero
+// without one.
+func (c *Client) SettingsStats() CacheStats {
+	s := c.settings
+	if s == nil {
+		return CacheStats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return newCacheStats(len(s.entries), s.hits, s.misses)
+}
+
+func newCacheStats(entries int, hits, misses int64) CacheStats {
+	stats := CacheStats{Entries: entries, Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		stats.HitRate = float64(hits) / float64(total)
+	}
+	return stats
+}
+
+// credentialID identifies an API key without keeping it in memory twice.
+func credentialID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,118 @@
+// Package completion completes the ID arguments of prompts and resource
+// templates from the IRIS objects they refer to, so that analysts can type
+// part of a name instead of looking up the ID.
+package completion
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/lookup"
+	"dfir-iris-mcp/internal/model"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxValues is the number of completions the MCP specification allows in a
+// response.
+const maxValues = 100
+
+// Handler returns the mcp.ServerOptions.CompletionHandler completing the
+// arguments listed in lookup.Sources, and note_id within the case_id
+// already given.
+// Completions are IDs; the partial value matches the start of an ID or any
+// part of a name, ignoring case.
+func Handler(c *client.Client) func(context.Context, *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	return func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		arg := req.Params.Argument
+		var given map[string]string
+		if req.Params.Context != nil {
+			given = req.Params.Context.Arguments
+		}
+		entries, err := candidates(ctx, c, arg.Name, given)
+		if err != nil {
+			return nil, fmt.Errorf("completing %s: %w", arg.Name, err)
+		}
+		values := match(entries, arg.Value)
+		res := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: values, Total: len(values)}}
+		if len(values) > maxValues {
+			res.Completion.Values = values[:maxValues]
+			res.Completion.HasMore = true
+		}
+		return res, nil
+	}
+}
+
+// candidates lists the objects that the argument named arg can refer to.
+func candidates(ctx context.Context, c *client.Client, arg string, given map[string]string) ([]lookup.Entry, error) {
+	if src, ok := lookup.Sources[arg]; ok {
+		return lookup.List(ctx, c, src)
+	}
+	if arg != "note_id" {
+		return nil, nil
+	}
+	caseID, err := strconv.Atoi(given["case_id"])
+	if err != nil || caseID <= 0 {
+		return nil, nil
+	}
+	data, err := c.Get(ctx, "/case/notes/directories/filter", client.CaseQuery(caseID))
+	if err != nil {
+		return nil, err
+	}
+	var dirs model.NoteDirectoryList
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		return nil, fmt.Errorf("decoding note directories: %w", err)
+	}
+	var entries []lookup.Entry
+	for _, dir := range dirs.Directories {
+		for _, note := range dir.Notes {
+			entries = append(entries, lookup.Entry{ID: note.ID, Name: note.Title})
+		}
+	}
+	return entries, nil
+}
+
+// match returns the IDs of the entries that partial matches, best matches
+// first: the exact ID, IDs starting with partial, names starting with it,
+// then names containing it.
+func match(entries []lookup.Entry, partial string) []string {
+	partial = strings.ToLower(strings.TrimSpace(partial))
+	type scored struct {
+		entry lookup.Entry
+		rank  int
+	}
+	var found []scored
+	for _, e := range entries {
+		id, name := strconv.Itoa(e.ID), strings.ToLower(e.Name)
+		rank := -1
+		switch {
+		case partial == "":
+			rank = 0
+		case id == partial:
+			rank = 0
+		case strings.HasPrefix(id, partial):
+			rank = 1
+		case strings.HasPrefix(name, partial):
+			rank = 2
+		case strings.Contains(name, partial):
+			rank = 3
+		}
+		if rank >= 0 {
+			found = append(found, scored{e, rank})
+		}
+	}
+	slices.SortStableFunc(found, func(a, b scored) int {
+		return cmp.Or(cmp.Compare(a.rank, b.rank), strings.Compare(a.entry.Name, b.entry.Name))
+	})
+	values := make([]string, 0, len(found))
+	for _, f := range found {
+		values = append(values, strconv.Itoa(f.entry.ID))
+	}
+	return values
+}
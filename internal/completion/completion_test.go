@@ -0,0 +1,58 @@
+package completion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHandler(t *testing.T) {
+	lists := map[string]string{
+		"/manage/customers/list":            `[{"customer_id":3,"customer_name":"ACME"},{"customer_id":4,"customer_name":"Globex"},{"customer_id":34,"customer_name":"Initech"}]`,
+		"/manage/case-templates/list":       `[{"id":2,"display_name":"Ransomware playbook"},{"id":7,"display_name":"Phishing"}]`,
+		"/manage/case-classifications/list": `[{"id":4,"name":"malware:ransomware"},{"id":5,"name":"phishing"},{"id":6,"name":"a-phishing"}]`,
+		"/manage/ioc-types/list":            `[{"type_id":76,"type_name":"ip-dst"},{"type_id":77,"type_name":"ip-src"},{"type_id":20,"type_name":"domain"}]`,
+	}
+	iris := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := lists[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected IRIS request %s", r.URL.Path)
+			data = "null"
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":` + data + `}`))
+	}))
+	defer iris.Close()
+	complete := Handler(client.New(iris.URL, "key"))
+
+	for _, tt := range []struct {
+		arg, value string
+		want       []string
+	}{
+		{"customer_id", "", []string{"3", "4", "34"}},
+		{"customer_id", "3", []string{"3", "34"}},
+		{"customer_id", "glo", []string{"4"}},
+		{"case_template_id", "ransom", []string{"2"}},
+		// Names starting with the value come before names containing it.
+		{"classification_id", "phish", []string{"5", "6"}},
+		{"classification_id", "ware", []string{"4"}},
+		{"ioc_type_id", "ip", []string{"76", "77"}},
+		{"comment", "x", []string{}},
+	} {
+		res, err := complete(context.Background(), &mcp.CompleteRequest{Params: &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "open_case"},
+			Argument: mcp.CompleteParamsArgument{Name: tt.arg, Value: tt.value},
+		}})
+		if err != nil {
+			t.Fatalf("completing %s %q: %v", tt.arg, tt.value, err)
+		}
+		if got := res.Completion.Values; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completing %s %q = %q, want %q", tt.arg, tt.value, got, tt.want)
+		}
+	}
+}
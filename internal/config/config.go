@@ -3,25 +3,562 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// Config is loaded from an optional YAML file, with environment variables
+// taking precedence over values set in the file.
 type Config struct {
-	BaseURL string
-	APIKey  string
+	BaseURL string `yaml:"url"`
+	APIKey  string `yaml:"api_key"`
+	// APIKeyFile names a file holding the API key, such as a mounted
+	// secret. It is read once at startup.
+	APIKeyFile string `yaml:"api_key_file"`
+
+	// Profile is the name of the selected entry in Profiles, if any.
+	Profile string `yaml:"profile"`
+	// Profiles are named sets of settings layered over the top-level ones,
+	// typically one per IRIS instance. Any top-level key may appear in a
+	// profile except profile and profiles.
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+	// Instances names profiles served alongside the selected one. Tools
+	// then take an instance argument choosing the deployment to call.
+	Instances []string `yaml:"instances"`
+	// Peers holds the loaded configuration of each entry in Instances.
+	// Environment variables apply to the primary configuration only.
+	Peers map[string]*Config `yaml:"-"`
+
+	// Listen is the address to serve MCP over HTTP on. Empty means stdio.
+	Listen string `yaml:"listen"`
+	// SessionTimeout closes idle HTTP sessions. Zero disables the timeout.
+	SessionTimeout time.Duration `yaml:"session_timeout"`
+	// SessionAuth requires every HTTP session to supply its own IRIS API
+	// key as a Bearer token. APIKey is then optional.
+	SessionAuth bool `yaml:"session_auth"`
+	// ReadOnly exposes only non-mutating tools and makes the client refuse
+	// any non-search POST.
+	ReadOnly bool `yaml:"read_only"`
+	// Tools restricts which tools are registered.
+	Tools ToolFilter `yaml:"tools"`
+	// Retry controls retries of failed IRIS requests.
+	Retry Retry `yaml:"retry"`
+	// Limits throttles requests to IRIS across all sessions.
+	Limits Limits `yaml:"limits"`
+	// HTTP configures timeouts and the proxy used to reach IRIS.
+	HTTP HTTP `yaml:"http"`
+	// TLS configures certificate verification and client certificates.
+	TLS TLS `yaml:"tls"`
+	// Output limits the size of read tool output.
+	Output Output `yaml:"output"`
+	// Subscriptions configures change polling for subscribed resources.
+	Subscriptions Subscriptions `yaml:"subscriptions"`
+	// Cache configures the caching of IRIS responses.
+	Cache Cache `yaml:"cache"`
+	// Audit configures the audit log of tool calls.
+	Audit Audit `yaml:"audit"`
+	// Redaction masks secrets and personal data in audit logs, error
+	// messages and tool output.
+	Redaction Redaction `yaml:"redaction"`
+	// Telemetry configures metrics and tracing.
+	Telemetry Telemetry `yaml:"telemetry"`
+	// Log configures the server log, which is always written to stderr.
+	Log Log `yaml:"log"`
+}
+
+// ToolFilter selects tools by domain (e.g. "cases") or by a glob on the
+// tool name (e.g. "dfir_iris_*_delete"). An empty Include selects every
+// tool; Exclude is applied afterwards.
+type ToolFilter struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// Retry configures retries with jittered exponential backoff.
+type Retry struct {
+	MaxRetries int           `yaml:"max_retries"`
+	BaseDelay  time.Duration `yaml:"base_delay"`
+	MaxDelay   time.Duration `yaml:"max_delay"`
+}
+
+// Limits configures client-side throttling. Zero values disable a limit.
+type Limits struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+	MaxInFlight       int     `yaml:"max_in_flight"`
+}
+
+// HTTP configures the connection to IRIS.
+type HTTP struct {
+	// Timeout bounds a single request attempt. Zero means no timeout.
+	Timeout             time.Duration `yaml:"timeout"`
+	DialTimeout         time.Duration `yaml:"dial_timeout"`
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout"`
+	// Proxy is an explicit HTTP(S) proxy URL. If empty, the standard
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY variables apply.
+	Proxy string `yaml:"proxy"`
+}
+
+// TLS configures trust and client authentication toward IRIS.
+type TLS struct {
+	CABundle   string `yaml:"ca_bundle"`
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
+	SkipVerify bool   `yaml:"skip_verify"`
+}
+
+// Output sets the budget above which read tool output is truncated. Tokens
+// are estimated at four bytes each; the smaller budget applies. Zero values
+// disable a limit.
+type Output struct {
+	MaxBytes  int `yaml:"max_bytes"`
+	MaxTokens int `yaml:"max_tokens"`
+	// FetchAllLimit caps the items collected by a fetch_all filter call.
+	FetchAllLimit int `yaml:"fetch_all_limit"`
+}
+
+// MinPollInterval is the shortest accepted Subscriptions.PollInterval. Each
+// poll makes several requests per subscribed case, so shorter intervals
+// would mostly load IRIS.
+const MinPollInterval = 10 * time.Second
+
+// Subscriptions configures resource subscriptions. A zero PollInterval
+// disables them; otherwise it must be at least MinPollInterval. A zero Max
+// allows any number.
+type Subscriptions struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Max          int           `yaml:"max"`
+}
+
+// Cache configures response caching. A zero SettingsTTL disables the
+// settings cache; WarmUp loads it at startup. A zero MaxEntries disables the
+// cache of other GET responses, which are kept for the TTL of the first rule
+// matching their path, or else TTL.
+type Cache struct {
+	SettingsTTL time.Duration `yaml:"settings_ttl"`
+	WarmUp      bool          `yaml:"warm_up"`
+	MaxEntries  int           `yaml:"max_entries"`
+	TTL         time.Duration `yaml:"ttl"`
+	Rules       []CacheRule   `yaml:"rules"`
+}
+
+// CacheRule sets the TTL of the responses whose path matches the glob
+// Path, e.g. "/manage/*/list".
+type CacheRule struct {
+	Path string        `yaml:"path"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+// Audit configures the audit log. An empty Path disables it; "-" writes to
+// stdout, which is only possible with --listen. A zero MaxBytes disables
+// rotation; otherwise MaxFiles must be at least 1.
+type Audit struct {
+	Path      string `yaml:"path"`
+	MaxBytes  int    `yaml:"max_bytes"`
+	MaxFiles  int    `yaml:"max_files"`
+	HashChain bool   `yaml:"hash_chain"`
+}
+
+// Redaction masks the values of arguments whose names contain one of Keys,
+// ignoring case, in audit logs, and the text matching Rules in audit logs and
+// error messages.
+type Redaction struct {
+	Keys  []string        `yaml:"keys"`
+	Rules []RedactionRule `yaml:"rules"`
+}
+
+// RedactionRule replaces the matches of Regex with Replace, which may refer
+// to submatches as ${1}. An empty Regex selects the built-in rule called
+// Name, such as "email". Outputs applies the rule to tool output as well.
+type RedactionRule struct {
+	Name    string `yaml:"name"`
+	Regex   string `yaml:"regex"`
+	Replace string `yaml:"replace"`
+	Outputs bool   `yaml:"outputs"`
+}
+
+// Telemetry configures observability. An empty MetricsListen disables the
+// Prometheus metrics listener, and an empty Tracing disables tracing;
+// "otlp" exports spans to the collector set by the standard
+// OTEL_EXPORTER_OTLP_* variables.
+type Telemetry struct {
+	MetricsListen string `yaml:"metrics_listen"`
+	Tracing       string `yaml:"tracing"`
+}
+
+// Log sets the level ("debug", "info", "warn" or "error") and format ("text"
+// or "json") of the server log.
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Budget returns the output budget in bytes, or zero if unlimited.
+func (o Output) Budget() int {
+	budget := o.MaxBytes
+	if tb := o.MaxTokens * 4; tb > 0 && (budget <= 0 || tb < budget) {
+		budget = tb
+	}
+	return max(budget, 0)
+}
+
+// Load reads the configuration file at path, if non-empty, layers the named
+// profile over it and applies environment overrides on top. An empty profile
+// selects the one named by the profile key of the file, if any. The profiles
+// listed in Instances are loaded into Peers.
+func Load(path, profile string) (*Config, error) {
+	cfg, err := load(path, profile, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range cfg.Instances {
+		if name == cfg.Name() || cfg.Peers[name] != nil {
+			continue
+		}
+		peer, err := load(path, name, false)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", name, err)
+		}
+		if cfg.Peers == nil {
+			cfg.Peers = make(map[string]*Config)
+		}
+		cfg.Peers[name] = peer
+	}
+	if cfg.SessionAuth && len(cfg.Peers) > 0 {
+		return nil, fmt.Errorf("session_auth cannot be combined with multiple instances: a session's API key is only valid for one of them")
+	}
+	return cfg, nil
+}
+
+// Name identifies the configuration among instances: its profile name, or
+// "default" when no profile is selected.
+func (cfg *Config) Name() string {
+	if cfg.Profile == "" {
+		return "default"
+	}
+	return cfg.Profile
+}
+
+func load(path, profile string, env bool) (*Config, error) {
+	cfg := &Config{
+		SessionTimeout: 30 * time.Minute,
+		Retry: Retry{
+			MaxRetries: 3,
+			BaseDelay:  500 * time.Millisecond,
+			MaxDelay:   30 * time.Second,
+		},
+		Limits: Limits{
+			RequestsPerSecond: 10,
+			Burst:             20,
+			MaxInFlight:       8,
+		},
+		HTTP: HTTP{
+			Timeout:             60 * time.Second,
+			DialTimeout:         10 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		Output:        Output{MaxBytes: 100_000, FetchAllLimit: 1000},
+		Subscriptions: Subscriptions{PollInterval: time.Minute, Max: 100},
+		Cache:         Cache{SettingsTTL: 10 * time.Minute, WarmUp: true, TTL: 30 * time.Second},
+		Audit:         Audit{MaxBytes: 100 << 20, MaxFiles: 5},
+		Log:           Log{Level: "info", Format: "text"},
+		Redaction: Redaction{
+			Keys: []string{"password", "secret", "token", "api_key", "apikey"},
+			Rules: []RedactionRule{
+				{Name: "credential"}, {Name: "bearer"}, {Name: "jwt"}, {Name: "private_key"},
+			},
+		},
+	}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(b, &root); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+		if err := cfg.apply(&root); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+	if profile != "" {
+		cfg.Profile = profile
+	}
+	if cfg.Profile != "" {
+		if err := cfg.applyProfile(cfg.Profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if env {
+		if err := cfg.applyEnv(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.APIKey == "" && cfg.APIKeyFile != "" {
+		b, err := os.ReadFile(cfg.APIKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading API key file: %w", err)
+		}
+		cfg.APIKey = strings.TrimSpace(string(b))
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("API key file %s is empty", cfg.APIKeyFile)
+		}
+	}
+
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("DFIR_IRIS_URL environment variable (or url in the config file) is required")
+	}
+	if cfg.APIKey == "" && !cfg.SessionAuth {
+		return nil, fmt.Errorf("DFIR_IRIS_API_KEY environment variable (or api_key in the config file) is required")
+	}
+	if cfg.Retry.MaxRetries < 0 {
+		return nil, fmt.Errorf("retry count must not be negative")
+	}
+	if a := cfg.Audit; a.Path != "" && a.MaxBytes > 0 && a.MaxFiles < 1 {
+		return nil, fmt.Errorf("audit max_files must be at least 1 when the log is rotated (max_bytes > 0), not %d", a.MaxFiles)
+	}
+	if p := cfg.Subscriptions.PollInterval; p < 0 || p > 0 && p < MinPollInterval {
+		return nil, fmt.Errorf("subscription poll interval must be 0 (disabled) or at least %s, not %s", MinPollInterval, p)
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return cfg, nil
+}
+
+// apply decodes one layer of settings (the file itself or a profile) over
+// cfg. Setting one of api_key and api_key_file discards the other from lower
+// layers, so a profile can switch from an inline key to a key file.
+func (cfg *Config) apply(node *yaml.Node) error {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	hasKey, hasKeyFile := hasField(node, "api_key"), hasField(node, "api_key_file")
+	if hasKey && hasKeyFile {
+		return fmt.Errorf("api_key and api_key_file are mutually exclusive")
+	}
+	if hasKey {
+		cfg.APIKeyFile = ""
+	}
+	if hasKeyFile {
+		cfg.APIKey = ""
+	}
+	return node.Decode(cfg)
+}
+
+// applyProfile layers the named profile over cfg.
+func (cfg *Config) applyProfile(name string) error {
+	node, ok := cfg.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(cfg.Profiles))
+		for n := range cfg.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("profile %q requested but no profiles are configured", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	if hasField(&node, "profile") || hasField(&node, "profiles") {
+		return fmt.Errorf("profile %q: profiles cannot be nested", name)
+	}
+	if err := cfg.apply(&node); err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// hasField reports whether the mapping node sets key.
+func hasField(node *yaml.Node, key string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *Config) applyEnv() error {
+	if v := os.Getenv("DFIR_IRIS_URL"); v != "" {
+		cfg.BaseURL = v
+	}
+	if v := os.Getenv("DFIR_IRIS_API_KEY_FILE"); v != "" {
+		cfg.APIKeyFile = v
+		cfg.APIKey = ""
+	}
+	if v := os.Getenv("DFIR_IRIS_API_KEY"); v != "" {
+		cfg.APIKey = v
+	}
+	if v := os.Getenv("DFIR_IRIS_LISTEN"); v != "" {
+		cfg.Listen = v
+	}
+	if v := os.Getenv("DFIR_IRIS_PROXY"); v != "" {
+		cfg.HTTP.Proxy = v
+	}
+	if v := os.Getenv("DFIR_IRIS_CA_BUNDLE"); v != "" {
+		cfg.TLS.CABundle = v
+	}
+	if v := os.Getenv("DFIR_IRIS_CLIENT_CERT"); v != "" {
+		cfg.TLS.ClientCert = v
+	}
+	if v := os.Getenv("DFIR_IRIS_CLIENT_KEY"); v != "" {
+		cfg.TLS.ClientKey = v
+	}
+	// Historically any non-empty value enables this.
+	if os.Getenv("DFIR_IRIS_TLS_SKIP_VERIFY") != "" {
+		cfg.TLS.SkipVerify = true
+	}
+	envList("DFIR_IRIS_TOOLS_INCLUDE", &cfg.Tools.Include)
+	envList("DFIR_IRIS_TOOLS_EXCLUDE", &cfg.Tools.Exclude)
+	envList("DFIR_IRIS_INSTANCES", &cfg.Instances)
+	if v := os.Getenv("DFIR_IRIS_AUDIT_LOG"); v != "" {
+		cfg.Audit.Path = v
+	}
+	if v := os.Getenv("DFIR_IRIS_METRICS_LISTEN"); v != "" {
+		cfg.Telemetry.MetricsListen = v
+	}
+	if v := os.Getenv("DFIR_IRIS_TRACING"); v != "" {
+		cfg.Telemetry.Tracing = v
+	}
+	if v := os.Getenv("DFIR_IRIS_LOG_LEVEL"); v != "" {
+		cfg.Log.Level = v
+	}
+	if v := os.Getenv("DFIR_IRIS_LOG_FORMAT"); v != "" {
+		cfg.Log.Format = v
+	}
+	envList("DFIR_IRIS_REDACT_KEYS", &cfg.Redaction.Keys)
+	var rules, outputs []string
+	envList("DFIR_IRIS_REDACT_RULES", &rules)
+	envList("DFIR_IRIS_REDACT_OUTPUTS", &outputs)
+	if rules != nil {
+		cfg.Redaction.Rules = nil
+		for _, name := range rules {
+			cfg.Redaction.Rules = append(cfg.Redaction.Rules, RedactionRule{Name: name})
+		}
+	}
+	cfg.Redaction.setOutputs(outputs)
+	for _, err := range []error{
+		envDuration("DFIR_IRIS_SESSION_TIMEOUT", &cfg.SessionTimeout),
+		envBool("DFIR_IRIS_SESSION_AUTH", &cfg.SessionAuth),
+		envBool("DFIR_IRIS_READ_ONLY", &cfg.ReadOnly),
+		envInt("DFIR_IRIS_MAX_RETRIES", &cfg.Retry.MaxRetries),
+		envDuration("DFIR_IRIS_RETRY_BASE_DELAY", &cfg.Retry.BaseDelay),
+		envDuration("DFIR_IRIS_RETRY_MAX_DELAY", &cfg.Retry.MaxDelay),
+		envFloat("DFIR_IRIS_RATE_LIMIT", &cfg.Limits.RequestsPerSecond),
+		envInt("DFIR_IRIS_RATE_BURST", &cfg.Limits.Burst),
+		envInt("DFIR_IRIS_MAX_IN_FLIGHT", &cfg.Limits.MaxInFlight),
+		envDuration("DFIR_IRIS_TIMEOUT", &cfg.HTTP.Timeout),
+		envDuration("DFIR_IRIS_DIAL_TIMEOUT", &cfg.HTTP.DialTimeout),
+		envDuration("DFIR_IRIS_TLS_HANDSHAKE_TIMEOUT", &cfg.HTTP.TLSHandshakeTimeout),
+		envInt("DFIR_IRIS_OUTPUT_MAX_BYTES", &cfg.Output.MaxBytes),
+		envInt("DFIR_IRIS_OUTPUT_MAX_TOKENS", &cfg.Output.MaxTokens),
+		envInt("DFIR_IRIS_FETCH_ALL_LIMIT", &cfg.Output.FetchAllLimit),
+		envDuration("DFIR_IRIS_POLL_INTERVAL", &cfg.Subscriptions.PollInterval),
+		envInt("DFIR_IRIS_MAX_SUBSCRIPTIONS", &cfg.Subscriptions.Max),
+		envDuration("DFIR_IRIS_SETTINGS_TTL", &cfg.Cache.SettingsTTL),
+		envBool("DFIR_IRIS_SETTINGS_WARM_UP", &cfg.Cache.WarmUp),
+		envInt("DFIR_IRIS_CACHE_MAX_ENTRIES", &cfg.Cache.MaxEntries),
+		envDuration("DFIR_IRIS_CACHE_TTL", &cfg.Cache.TTL),
+		envInt("DFIR_IRIS_AUDIT_MAX_BYTES", &cfg.Audit.MaxBytes),
+		envInt("DFIR_IRIS_AUDIT_MAX_FILES", &cfg.Audit.MaxFiles),
+		envBool("DFIR_IRIS_AUDIT_HASH_CHAIN", &cfg.Audit.HashChain),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOutputs applies the rules called names to tool output, adding those
+// not yet selected.
+func (r *Redaction) setOutputs(names []string) {
+	for _, name := range names {
+		i := slices.IndexFunc(r.Rules, func(rule RedactionRule) bool { return rule.Name == name })
+		if i < 0 {
+			r.Rules = append(r.Rules, RedactionRule{Name: name, Outputs: true})
+			continue
+		}
+		r.Rules[i].Outputs = true
+	}
+}
+
+// envBool overrides *dst with an optional boolean environment variable.
+func envBool(name string, dst *bool) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = b
+	return nil
+}
+
+// envInt overrides *dst with an optional integer environment variable.
+func envInt(name string, dst *int) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
+
+// envFloat overrides *dst with an optional numeric environment variable.
+func envFloat(name string, dst *float64) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = f
+	return nil
+}
+
+// envDuration overrides *dst with an optional duration environment variable.
+func envDuration(name string, dst *time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = d
+	return nil
 }
 
-func Load() (*Config, error) {
-	u := os.Getenv("DFIR_IRIS_URL")
-	if u == "" {
-		return nil, fmt.Errorf("DFIR_IRIS_URL environment variable is required")
+// envList overrides *dst with an optional comma-separated environment variable.
+func envList(name string, dst *[]string) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
 	}
-	k := os.Getenv("DFIR_IRIS_API_KEY")
-	if k == "" {
-		return nil, fmt.Errorf("DFIR_IRIS_API_KEY environment variable is required")
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return &Config{
-		BaseURL: strings.TrimRight(u, "/"),
-		APIKey:  k,
-	}, nil
+	*dst = items
 }
@@ -0,0 +1,163 @@
+// Package logging builds the structured logger of the server and carries
+// per-request loggers in contexts.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// New returns a logger writing to w, which is stderr in practice since
+// stdout may carry JSON-RPC, at level ("debug", "info", "warn" or "error")
+// in format ("text" or "json").
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q (want debug, info, warn or error)", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "text", "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q (want text or json)", format)
+}
+
+type loggerContextKey struct{}
+
+// WithLogger returns a context carrying l, typically annotated with the ID
+// of the request being served.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger of ctx, or fallback if it has none, or
+// else the default logger.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return slog.Default()
+}
+
+// NewRequestID returns a random ID correlating the log lines, spans and
+// audit records of a request.
+func NewRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Tee returns a handler sending each record to every handler enabled for
+// its level, e.g. to stderr and to the MCP client.
+func Tee(handlers ...slog.Handler) slog.Handler {
+	return tee(handlers)
+}
+
+type tee []slog.Handler
+
+func (t tee) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t tee) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t tee) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(tee, len(t))
+	for i, h := range t {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (t tee) WithGroup(name string) slog.Handler {
+	out := make(tee, len(t))
+	for i, h := range t {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// Redact returns a handler passing records to h with their message and
+// string and error attributes masked by mask, since error messages may quote
+// secrets. A nil mask returns h itself.
+func Redact(h slog.Handler, mask func(string) string) slog.Handler {
+	if mask == nil {
+		return h
+	}
+	return &redacting{h: h, mask: mask}
+}
+
+type redacting struct {
+	h    slog.Handler
+	mask func(string) string
+}
+
+func (r *redacting) Enabled(ctx context.Context, level slog.Level) bool {
+	return r.h.Enabled(ctx, level)
+}
+
+func (r *redacting) Handle(ctx context.Context, rec slog.Record) error {
+	masked := slog.NewRecord(rec.Time, rec.Level, r.mask(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(r.attr(a))
+		return true
+	})
+	return r.h.Handle(ctx, masked)
+}
+
+func (r *redacting) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = r.attr(a)
+	}
+	return &redacting{h: r.h.WithAttrs(masked), mask: r.mask}
+}
+
+func (r *redacting) WithGroup(name string) slog.Handler {
+	return &redacting{h: r.h.WithGroup(name), mask: r.mask}
+}
+
+func (r *redacting) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.mask(v.String()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		masked := make([]any, len(attrs))
+		for i, ga := range attrs {
+			masked[i] = r.attr(ga)
+		}
+		return slog.Group(a.Key, masked...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, r.mask(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
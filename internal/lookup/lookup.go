@@ -0,0 +1,176 @@
+// Package lookup lists the IRIS objects that tool, prompt and resource
+// arguments refer to by numeric ID, such as IOC types or customers, with the
+// names analysts know them by.
+package lookup
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+)
+
+// Entry is an IRIS object by ID and name.
+type Entry struct {
+	ID   int
+	Name string
+}
+
+// Source describes the IRIS endpoint that lists the objects an ID refers
+// to.
+type Source struct {
+	Path  string
+	Query map[string]string
+	// Items is the key of the list in the response, or empty if the
+	// response is the list.
+	Items string
+	// ID and Name are the fields of each item holding its ID and name.
+	ID   string
+	Name string
+}
+
+// Sources are the lookups by the name of the argument holding the ID. Tools
+// resolve the types and categories given by name, and the arguments of
+// prompts and resource templates named after a source are completed from it.
+var Sources = map[string]Source{
+	"asset_type_id":      {Path: "/manage/asset-type/list", ID: "asset_id", Name: "asset_name"},
+	"ioc_type_id":        {Path: "/manage/ioc-types/list", ID: "type_id", Name: "type_name"},
+	"event_category_id":  {Path: "/manage/event-categories/list", ID: "id", Name: "name"},
+	"case_template_id":   {Path: "/manage/case-templates/list", ID: "id", Name: "display_name"},
+	"classification_id":  {Path: "/manage/case-classifications/list", ID: "id", Name: "name"},
+	"evidence_type_id":   {Path: "/manage/evidence-types/list", ID: "id", Name: "name"},
+	"task_status_id":     {Path: "/manage/task-status/list", ID: "id", Name: "status_name"},
+	"analysis_status_id": {Path: "/manage/analysis-status/list", ID: "id", Name: "name"},
+	"state_id":           {Path: "/manage/case-states/list", ID: "state_id", Name: "state_name"},
+	"customer_id":        {Path: "/manage/customers/list", ID: "customer_id", Name: "customer_name"},
+	"case_id":            {Path: "/manage/cases/list", ID: "case_id", Name: "name"},
+	"alert_id": {
+		Path:  "/alerts/filter",
+		Query: map[string]string{"per_page": "100", "sort": "desc"},
+		Items: "alerts",
+		ID:    "alert_id",
+		Name:  "alert_title",
+	},
+}
+
+// List fetches the entries of src.
+func List(ctx context.Context, c *client.Client, src Source) ([]Entry, error) {
+	data, err := c.Get(ctx, src.Path, src.Query)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, src)
+}
+
+// Item fetches the object of src with the given ID, as IRIS lists it, or
+// nil if there is none.
+func Item(ctx context.Context, c *client.Client, src Source, id int) (json.RawMessage, error) {
+	data, err := c.Get(ctx, src.Path, src.Query)
+	if err != nil {
+		return nil, err
+	}
+	items, err := rawItems(data, src)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		var obj map[string]any
+		if json.Unmarshal(item, &obj) == nil && obj[src.ID] == float64(id) {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+// Decode extracts the entries of src from an IRIS response. Items without
+// an ID are skipped.
+func Decode(data json.RawMessage, src Source) ([]Entry, error) {
+	raw, err := rawItems(data, src)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]map[string]any, len(raw))
+	for i, item := range raw {
+		if err := json.Unmarshal(item, &items[i]); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", src.Path, err)
+		}
+	}
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		id, ok := item[src.ID].(float64)
+		if !ok {
+			continue
+		}
+		name, _ := item[src.Name].(string)
+		entries = append(entries, Entry{ID: int(id), Name: name})
+	}
+	return entries, nil
+}
+
+// rawItems returns the list of objects in an IRIS response of src.
+func rawItems(data json.RawMessage, src Source) ([]json.RawMessage, error) {
+	if src.Items != "" {
+		var wrapper map[string]json.RawMessage
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", src.Path, err)
+		}
+		data = wrapper[src.Items]
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", src.Path, err)
+	}
+	return items, nil
+}
+
+// Resolve returns the ID of the entry named name, ignoring case. If none
+// matches, the error lists the valid choices for arg, narrowed to those
+// containing name when there are any.
+func Resolve(entries []Entry, arg, name string) (int, error) {
+	var found []Entry
+	for _, e := range entries {
+		if strings.EqualFold(e.Name, name) {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 1:
+		return found[0].ID, nil
+	case 0:
+		choices := entries
+		var near []Entry
+		for _, e := range entries {
+			if strings.Contains(strings.ToLower(e.Name), strings.ToLower(name)) {
+				near = append(near, e)
+			}
+		}
+		if len(near) > 0 {
+			choices = near
+		}
+		return 0, &client.ArgumentError{
+			Field:   arg,
+			Message: fmt.Sprintf("unknown %s %q: valid choices are %s", arg, name, describe(choices)),
+		}
+	}
+	return 0, &client.ArgumentError{
+		Field:   arg,
+		Message: fmt.Sprintf("ambiguous %s %q: pass one of the IDs %s", arg, name, describe(found)),
+	}
+}
+
+// describe lists entries as "name (ID)", sorted by name.
+func describe(entries []Entry) string {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.ID, b.ID))
+	})
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = fmt.Sprintf("%s (%d)", e.Name, e.ID)
+	}
+	return strings.Join(parts, ", ")
+}
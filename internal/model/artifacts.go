@@ -0,0 +1,216 @@
+package model
+
+import "encoding/json"
+
+// Asset is a host, account or other entity involved in a case.
+type Asset struct {
+	ID                 int    `json:"asset_id"`
+	UUID               string `json:"asset_uuid,omitempty"`
+	Name               string `json:"asset_name"`
+	Description        string `json:"asset_description,omitempty"`
+	TypeID             int    `json:"asset_type_id,omitempty"`
+	Type               string `json:"asset_type,omitempty"`
+	IP                 string `json:"asset_ip,omitempty"`
+	Domain             string `json:"asset_domain,omitempty"`
+	Tags               string `json:"asset_tags,omitempty"`
+	CompromiseStatusID int    `json:"asset_compromise_status_id,omitempty"`
+	AnalysisStatusID   int    `json:"analysis_status_id,omitempty"`
+	AnalysisStatus     string `json:"analysis_status,omitempty"`
+}
+
+func (a *Asset) UnmarshalJSON(b []byte) error {
+	type plain Asset
+	var raw struct {
+		plain
+		Type           json.RawMessage `json:"asset_type"`
+		AnalysisStatus json.RawMessage `json:"analysis_status"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*a = Asset(raw.plain)
+	a.Type = nameOf(raw.Type, "asset_name")
+	a.AnalysisStatus = nameOf(raw.AnalysisStatus, "name")
+	return nil
+}
+
+// AssetList is the assets of a case.
+type AssetList struct {
+	Assets []Asset `json:"assets"`
+}
+
+// IOC is an indicator of compromise.
+type IOC struct {
+	ID          int    `json:"ioc_id"`
+	UUID        string `json:"ioc_uuid,omitempty"`
+	Value       string `json:"ioc_value"`
+	TypeID      int    `json:"ioc_type_id,omitempty"`
+	Type        string `json:"ioc_type,omitempty"`
+	Description string `json:"ioc_description,omitempty"`
+	Tags        string `json:"ioc_tags,omitempty"`
+	TLPID       int    `json:"ioc_tlp_id,omitempty"`
+	TLP         string `json:"tlp_name,omitempty"`
+}
+
+func (i *IOC) UnmarshalJSON(b []byte) error {
+	type plain IOC
+	var raw struct {
+		plain
+		Type json.RawMessage `json:"ioc_type"`
+		TLP  json.RawMessage `json:"tlp"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*i = IOC(raw.plain)
+	i.Type = nameOf(raw.Type, "type_name")
+	i.TLP = firstOf(i.TLP, nameOf(raw.TLP, "tlp_name"))
+	return nil
+}
+
+// IOCList is the IOCs of a case.
+type IOCList struct {
+	IOCs []IOC `json:"iocs"`
+}
+
+func (l *IOCList) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		IOC []IOC `json:"ioc"`
+	}
+	err := json.Unmarshal(b, &raw)
+	l.IOCs = raw.IOC
+	return err
+}
+
+// Event is an entry of a case timeline.
+type Event struct {
+	ID         int    `json:"event_id"`
+	UUID       string `json:"event_uuid,omitempty"`
+	Title      string `json:"event_title"`
+	Date       string `json:"event_date,omitempty"`
+	TZ         string `json:"event_tz,omitempty"`
+	Content    string `json:"event_content,omitempty"`
+	Raw        string `json:"event_raw,omitempty"`
+	Source     string `json:"event_source,omitempty"`
+	Tags       string `json:"event_tags,omitempty"`
+	CategoryID int    `json:"event_category_id,omitempty"`
+	Category   string `json:"category_name,omitempty"`
+	Color      string `json:"event_color,omitempty"`
+	InSummary  bool   `json:"event_in_summary,omitempty"`
+	InGraph    bool   `json:"event_in_graph,omitempty"`
+	AssetIDs   []int  `json:"asset_ids,omitempty"`
+	IOCIDs     []int  `json:"ioc_ids,omitempty"`
+	ParentID   int    `json:"parent_event_id,omitempty"`
+}
+
+func (e *Event) UnmarshalJSON(b []byte) error {
+	type plain Event
+	var raw struct {
+		plain
+		Category json.RawMessage   `json:"category"`
+		Assets   []json.RawMessage `json:"assets"`
+		IOCs     []json.RawMessage `json:"iocs"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*e = Event(raw.plain)
+	e.Category = firstOf(e.Category, nameOf(raw.Category, "name"))
+	for _, a := range raw.Assets {
+		if id := idOf(a, "asset_id", "id"); id != 0 {
+			e.AssetIDs = append(e.AssetIDs, id)
+		}
+	}
+	for _, i := range raw.IOCs {
+		if id := idOf(i, "ioc_id", "id"); id != 0 {
+			e.IOCIDs = append(e.IOCIDs, id)
+		}
+	}
+	return nil
+}
+
+// EventList is the timeline of a case.
+type EventList struct {
+	Events []Event `json:"events"`
+}
+
+func (l *EventList) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Timeline []Event `json:"timeline"`
+	}
+	err := json.Unmarshal(b, &raw)
+	l.Events = raw.Timeline
+	return err
+}
+
+// Task is a case task.
+type Task struct {
+	ID          int      `json:"task_id"`
+	UUID        string   `json:"task_uuid,omitempty"`
+	Title       string   `json:"task_title"`
+	Description string   `json:"task_description,omitempty"`
+	StatusID    int      `json:"task_status_id,omitempty"`
+	Status      string   `json:"status_name,omitempty"`
+	Tags        string   `json:"task_tags,omitempty"`
+	OpenDate    string   `json:"task_open_date,omitempty"`
+	LastUpdate  string   `json:"task_last_update,omitempty"`
+	CloseDate   string   `json:"task_close_date,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+}
+
+func (t *Task) UnmarshalJSON(b []byte) error {
+	type plain Task
+	var raw struct {
+		plain
+		AltID     int               `json:"id"`
+		Assignees []json.RawMessage `json:"task_assignees"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*t = Task(raw.plain)
+	t.ID = firstOf(t.ID, raw.AltID)
+	for _, a := range raw.Assignees {
+		if name := nameOf(a, "name", "user", "user_name", "user_login"); name != "" {
+			t.Assignees = append(t.Assignees, name)
+		}
+	}
+	return nil
+}
+
+// TaskList is the tasks of a case.
+type TaskList struct {
+	Tasks []Task `json:"tasks"`
+}
+
+// Evidence is a file or image registered as evidence in a case.
+type Evidence struct {
+	ID          int    `json:"id"`
+	UUID        string `json:"file_uuid,omitempty"`
+	Filename    string `json:"filename"`
+	Description string `json:"file_description,omitempty"`
+	Hash        string `json:"file_hash,omitempty"`
+	Size        int64  `json:"file_size,omitempty"`
+	DateAdded   string `json:"date_added,omitempty"`
+	TypeID      int    `json:"type_id,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+func (e *Evidence) UnmarshalJSON(b []byte) error {
+	type plain Evidence
+	var raw struct {
+		plain
+		Type json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*e = Evidence(raw.plain)
+	e.Type = nameOf(raw.Type, "name")
+	return nil
+}
+
+// EvidenceList is the evidences of a case.
+type EvidenceList struct {
+	Evidences []Evidence `json:"evidences"`
+}
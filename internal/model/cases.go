@@ -0,0 +1,117 @@
+package model
+
+import "encoding/json"
+
+// Case is an investigation case.
+type Case struct {
+	ID               int    `json:"case_id"`
+	UUID             string `json:"case_uuid,omitempty"`
+	Name             string `json:"name"`
+	Description      string `json:"description,omitempty"`
+	SOCID            string `json:"soc_id,omitempty"`
+	OpenDate         string `json:"open_date,omitempty"`
+	CloseDate        string `json:"close_date,omitempty"`
+	CustomerID       int    `json:"customer_id,omitempty"`
+	Customer         string `json:"customer_name,omitempty"`
+	StateID          int    `json:"state_id,omitempty"`
+	State            string `json:"state_name,omitempty"`
+	OwnerID          int    `json:"owner_id,omitempty"`
+	Owner            string `json:"owner,omitempty"`
+	ClassificationID int    `json:"classification_id,omitempty"`
+	Classification   string `json:"classification,omitempty"`
+}
+
+func (c *Case) UnmarshalJSON(b []byte) error {
+	type plain Case
+	var raw struct {
+		plain
+		ClientID       int             `json:"client_id"`
+		ClientName     string          `json:"client_name"`
+		Client         json.RawMessage `json:"client"`
+		State          json.RawMessage `json:"state"`
+		Owner          json.RawMessage `json:"owner"`
+		Classification json.RawMessage `json:"classification"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*c = Case(raw.plain)
+	c.CustomerID = firstOf(c.CustomerID, raw.ClientID, idOf(raw.Client, "customer_id"))
+	c.Customer = firstOf(c.Customer, raw.ClientName, nameOf(raw.Client, "customer_name"))
+	c.StateID = firstOf(c.StateID, idOf(raw.State, "state_id"))
+	c.State = firstOf(c.State, nameOf(raw.State, "state_name"))
+	c.OwnerID = firstOf(c.OwnerID, idOf(raw.Owner, "id", "user_id"))
+	c.Owner = nameOf(raw.Owner, "user_name", "user_login", "name")
+	c.ClassificationID = firstOf(c.ClassificationID, idOf(raw.Classification, "id"))
+	c.Classification = nameOf(raw.Classification, "name_expanded", "name")
+	return nil
+}
+
+// CaseList is the full list of cases.
+type CaseList struct {
+	Cases []Case `json:"cases"`
+}
+
+func (l *CaseList) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &l.Cases)
+}
+
+// CasePage is one page of a case filter.
+type CasePage struct {
+	Cases []Case `json:"cases"`
+	Page
+}
+
+// Alert is an alert received from a detection source.
+type Alert struct {
+	ID               int    `json:"alert_id"`
+	UUID             string `json:"alert_uuid,omitempty"`
+	Title            string `json:"alert_title"`
+	Description      string `json:"alert_description,omitempty"`
+	Source           string `json:"alert_source,omitempty"`
+	SourceRef        string `json:"alert_source_ref,omitempty"`
+	SourceLink       string `json:"alert_source_link,omitempty"`
+	SourceEventTime  string `json:"alert_source_event_time,omitempty"`
+	CreationTime     string `json:"alert_creation_time,omitempty"`
+	Note             string `json:"alert_note,omitempty"`
+	Tags             string `json:"alert_tags,omitempty"`
+	SeverityID       int    `json:"alert_severity_id,omitempty"`
+	Severity         string `json:"severity,omitempty"`
+	StatusID         int    `json:"alert_status_id,omitempty"`
+	Status           string `json:"status,omitempty"`
+	CustomerID       int    `json:"alert_customer_id,omitempty"`
+	Customer         string `json:"customer,omitempty"`
+	OwnerID          int    `json:"alert_owner_id,omitempty"`
+	Owner            string `json:"owner,omitempty"`
+	ClassificationID int    `json:"alert_classification_id,omitempty"`
+}
+
+func (a *Alert) UnmarshalJSON(b []byte) error {
+	type plain Alert
+	var raw struct {
+		plain
+		Severity      json.RawMessage `json:"severity"`
+		AlertSeverity json.RawMessage `json:"alert_severity"`
+		Status        json.RawMessage `json:"status"`
+		AlertStatus   json.RawMessage `json:"alert_status"`
+		Customer      json.RawMessage `json:"customer"`
+		AlertCustomer json.RawMessage `json:"alert_customer"`
+		Owner         json.RawMessage `json:"owner"`
+		AlertOwner    json.RawMessage `json:"alert_owner"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*a = Alert(raw.plain)
+	a.Severity = firstOf(nameOf(raw.Severity, "severity_name"), nameOf(raw.AlertSeverity, "severity_name"))
+	a.Status = firstOf(nameOf(raw.Status, "status_name"), nameOf(raw.AlertStatus, "status_name"))
+	a.Customer = firstOf(nameOf(raw.Customer, "customer_name"), nameOf(raw.AlertCustomer, "customer_name"))
+	a.Owner = firstOf(nameOf(raw.Owner, "user_name", "user_login"), nameOf(raw.AlertOwner, "user_name", "user_login"))
+	return nil
+}
+
+// AlertPage is one page of an alert filter.
+type AlertPage struct {
+	Alerts []Alert `json:"alerts"`
+	Page
+}
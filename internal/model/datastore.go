@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DatastoreNode is a folder or file of a case datastore.
+type DatastoreNode struct {
+	ID          int    `json:"id"`
+	Kind        string `json:"kind" jsonschema:"folder or file"`
+	Name        string `json:"name"`
+	ParentID    int    `json:"parent_id,omitempty" jsonschema:"ID of the containing folder, absent for the root"`
+	UUID        string `json:"file_uuid,omitempty"`
+	Description string `json:"file_description,omitempty"`
+	Size        int64  `json:"file_size,omitempty"`
+	Tags        string `json:"file_tags,omitempty"`
+	IsIOC       bool   `json:"file_is_ioc,omitempty"`
+	IsEvidence  bool   `json:"file_is_evidence,omitempty"`
+}
+
+// UnmarshalJSON decodes a file as returned by the datastore file endpoints.
+func (n *DatastoreNode) UnmarshalJSON(b []byte) error {
+	type plain DatastoreNode
+	var raw struct {
+		plain
+		FileID   int    `json:"file_id"`
+		FileName string `json:"file_original_name"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*n = DatastoreNode(raw.plain)
+	n.ID = firstOf(n.ID, raw.FileID)
+	n.Name = firstOf(n.Name, raw.FileName)
+	n.Kind = "file"
+	return nil
+}
+
+// DatastoreTree is the content of a case datastore, flattened so that every
+// node appears after its parent folder.
+type DatastoreTree struct {
+	Nodes []DatastoreNode `json:"nodes"`
+}
+
+// UnmarshalJSON decodes the tree returned by /datastore/list/tree, a map of
+// "d-<id>" folders and "f-<id>" files whose folders nest their entries in
+// children.
+func (t *DatastoreTree) UnmarshalJSON(b []byte) error {
+	var walk func(parent int, entries map[string]json.RawMessage) error
+	walk = func(parent int, entries map[string]json.RawMessage) error {
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kind, idText, _ := strings.Cut(k, "-")
+			id, _ := strconv.Atoi(idText)
+			if kind == "d" {
+				var folder struct {
+					Name     string                     `json:"name"`
+					Children map[string]json.RawMessage `json:"children"`
+				}
+				if err := json.Unmarshal(entries[k], &folder); err != nil {
+					return err
+				}
+				t.Nodes = append(t.Nodes, DatastoreNode{ID: id, Kind: "folder", Name: folder.Name, ParentID: parent})
+				if err := walk(id, folder.Children); err != nil {
+					return err
+				}
+				continue
+			}
+			var file DatastoreNode
+			if err := json.Unmarshal(entries[k], &file); err != nil {
+				return err
+			}
+			file.ID = firstOf(file.ID, id)
+			file.ParentID = parent
+			t.Nodes = append(t.Nodes, file)
+		}
+		return nil
+	}
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(b, &root); err != nil {
+		return err
+	}
+	t.Nodes = nil
+	return walk(0, root)
+}
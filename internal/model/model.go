@@ -0,0 +1,67 @@
+// Package model defines typed views of the objects returned by the DFIR-IRIS
+// API. IRIS serialises the same object differently depending on the endpoint
+// (a related object may be a plain name in a list and a nested object in a
+// filter result), so the types decode every known shape into one stable form
+// that is used as structured tool output.
+package model
+
+import "encoding/json"
+
+// Page describes one page of a paginated filter result.
+type Page struct {
+	Total       int  `json:"total"`
+	CurrentPage int  `json:"current_page,omitempty"`
+	LastPage    int  `json:"last_page,omitempty"`
+	NextPage    *int `json:"next_page,omitempty"`
+	// NextCursor is set when more results remain; pass it back as cursor.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// nameOf returns raw if it is a JSON string, or else the first non-empty
+// string found under keys if it is an object.
+func nameOf(raw json.RawMessage, keys ...string) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var obj map[string]any
+	if json.Unmarshal(raw, &obj) != nil {
+		return ""
+	}
+	for _, k := range keys {
+		if v, ok := obj[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// idOf returns raw if it is a JSON number, or else the first number found
+// under keys if it is an object.
+func idOf(raw json.RawMessage, keys ...string) int {
+	var n int
+	if json.Unmarshal(raw, &n) == nil {
+		return n
+	}
+	var obj map[string]any
+	if json.Unmarshal(raw, &obj) != nil {
+		return 0
+	}
+	for _, k := range keys {
+		if v, ok := obj[k].(float64); ok {
+			return int(v)
+		}
+	}
+	return 0
+}
+
+// firstOf returns the first non-zero of vs.
+func firstOf[T comparable](vs ...T) T {
+	var zero T
+	for _, v := range vs {
+		if v != zero {
+			return v
+		}
+	}
+	return zero
+}
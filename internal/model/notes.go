@@ -0,0 +1,82 @@
+package model
+
+import "encoding/json"
+
+// Note is a case note.
+type Note struct {
+	ID           int    `json:"note_id"`
+	UUID         string `json:"note_uuid,omitempty"`
+	Title        string `json:"note_title"`
+	Content      string `json:"note_content,omitempty"`
+	DirectoryID  int    `json:"directory_id,omitempty"`
+	CreationDate string `json:"note_creationdate,omitempty"`
+	LastUpdate   string `json:"note_lastupdate,omitempty"`
+}
+
+func (n *Note) UnmarshalJSON(b []byte) error {
+	type plain Note
+	var raw struct {
+		plain
+		AltID    int    `json:"id"`
+		AltTitle string `json:"title"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*n = Note(raw.plain)
+	n.ID = firstOf(n.ID, raw.AltID)
+	n.Title = firstOf(n.Title, raw.AltTitle)
+	return nil
+}
+
+// NoteList is a list of notes, such as search results.
+type NoteList struct {
+	Notes []Note `json:"notes"`
+}
+
+func (l *NoteList) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &l.Notes)
+}
+
+// NoteDirectory is a note directory (a "note group"). Nested directories
+// refer to their parent by ParentID.
+type NoteDirectory struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID int    `json:"parent_id,omitempty"`
+	Notes    []Note `json:"notes"`
+}
+
+// NoteDirectoryList is the note directories of a case, flattened so that
+// every directory appears after its parent.
+type NoteDirectoryList struct {
+	Directories []NoteDirectory `json:"directories"`
+}
+
+func (l *NoteDirectoryList) UnmarshalJSON(b []byte) error {
+	type tree struct {
+		NoteDirectory
+		Subdirectories []json.RawMessage `json:"subdirectories"`
+	}
+	var walk func(parent int, items []json.RawMessage) error
+	walk = func(parent int, items []json.RawMessage) error {
+		for _, item := range items {
+			var d tree
+			if err := json.Unmarshal(item, &d); err != nil {
+				return err
+			}
+			d.ParentID = firstOf(d.ParentID, parent)
+			l.Directories = append(l.Directories, d.NoteDirectory)
+			if err := walk(d.ID, d.Subdirectories); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var top []json.RawMessage
+	if err := json.Unmarshal(b, &top); err != nil {
+		return err
+	}
+	l.Directories = nil
+	return walk(0, top)
+}
@@ -0,0 +1,477 @@
+// Package prompts provides MCP prompts for standard incident response
+// workflows. Each prompt loads the IRIS data it needs and packages it with
+// instructions, so that analysts get consistent output across the team.
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/lookup"
+	"dfir-iris-mcp/internal/model"
+	"dfir-iris-mcp/internal/redact"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxHuntedIOCs caps the IOCs of a case searched in other cases, one search
+// each.
+const maxHuntedIOCs = 25
+
+// prompt is a prompt taking an IRIS ID, and how to build it.
+type prompt struct {
+	name        string
+	title       string
+	description string
+	arg         string // name of the ID argument
+	argDesc     string
+	options     []option
+	tools       string // tools reading the data, named when some is left out
+	build       func(ctx context.Context, b *builder, id int) error
+}
+
+// option is an optional argument of a prompt: an IRIS ID, or free text.
+type option struct {
+	name string
+	desc string
+	text bool
+}
+
+var prompts = []prompt{
+	{
+		name:        "triage_alert",
+		title:       "Triage alert",
+		description: "Triage a DFIR-IRIS alert: assess severity, decide whether to escalate to a case and list next steps",
+		arg:         "alert_id",
+		argDesc:     "ID of the alert to triage",
+		tools:       "dfir_iris_alerts_get",
+		build: func(ctx context.Context, b *builder, id int) error {
+			b.instructions(fmt.Sprintf(`Triage DFIR-IRIS alert %d using the data below.
+
+Produce:
+1. **Verdict**: true positive, false positive or needs investigation, with your confidence and the evidence it rests on.
+2. **Severity**: whether the recorded severity is right, and why.
+3. **Key observables**: the IOCs and assets involved, and what each tells us.
+4. **Recommendation**: close, escalate to a new case, or merge into an existing case, with the reason.
+5. **Next steps**: concrete checks for the analyst, most important first.
+
+Do not change the alert yourself; propose the tool calls (e.g. dfir_iris_alerts_update, dfir_iris_alerts_escalate) for the analyst to approve.`, id))
+			return b.section("Alert", fmt.Sprintf("/alerts/%d", id), nil)
+		},
+	},
+	{
+		name:        "executive_summary",
+		title:       "Write executive summary",
+		description: "Write an executive summary of a DFIR-IRIS case for management, from its details, assets, IOCs, timeline and tasks",
+		arg:         "case_id",
+		argDesc:     "ID of the case to summarise",
+		tools:       "dfir_iris_cases_list, dfir_iris_assets_list, dfir_iris_iocs_list, dfir_iris_timeline_list and dfir_iris_tasks_list",
+		build: func(ctx context.Context, b *builder, id int) error {
+			b.instructions(fmt.Sprintf(`Write an executive summary of DFIR-IRIS case %d for a non-technical audience, using the data below.
+
+Use these sections, in Markdown:
+- **Overview**: what happened, when it was detected and the current status, in three sentences at most.
+- **Impact**: affected systems, data and business processes.
+- **Timeline**: the key moments only, in chronological order.
+- **Response**: what has been done and what remains, based on the tasks.
+- **Recommendations**: the decisions or investments management should consider.
+
+Avoid jargon and raw indicators. Say plainly what is not known yet. Do not invent facts that are not in the data.`, id))
+			q := client.CaseQuery(id)
+			return b.sections(
+				sectionSpec{"Case", fmt.Sprintf("/manage/cases/%d", id), nil},
+				sectionSpec{"Assets", "/case/assets/list", q},
+				sectionSpec{"IOCs", "/case/ioc/list", q},
+				sectionSpec{"Timeline", "/case/timeline/events/list", q},
+				sectionSpec{"Tasks", "/case/tasks/list", q},
+			)
+		},
+	},
+	{
+		name:        "timeline_from_notes",
+		title:       "Build timeline from notes",
+		description: "Extract timestamped events from the notes of a DFIR-IRIS case and propose timeline entries",
+		arg:         "case_id",
+		argDesc:     "ID of the case whose notes to read",
+		options: []option{
+			{name: "event_category_id", desc: "ID of the event category to give every proposed event, instead of a MITRE ATT&CK tactic per event"},
+		},
+		tools: "dfir_iris_notes_groups_list and dfir_iris_notes_get",
+		build: func(ctx context.Context, b *builder, id int) error {
+			category := "a MITRE ATT&CK tactic as category when one applies."
+			if cat := b.optionID("event_category_id"); cat > 0 {
+				category = fmt.Sprintf("event category %d (see below) for every event.", cat)
+			}
+			b.instructions(fmt.Sprintf(`Build a timeline for DFIR-IRIS case %d from its notes, below.
+
+For every event the notes describe with a date and time:
+- give the timestamp in ISO 8601 with its timezone (state your assumption if the note gives none),
+- a short title, a one-line description and the note it comes from,
+- the assets and IOCs involved, matched to the case assets and IOCs where possible,
+- %s
+
+Skip events already in the existing timeline. Present the result as a table sorted by time, then propose dfir_iris_timeline_add calls for the analyst to approve; do not add events yourself.`, id, category))
+			if err := b.setting("Event category", "event_category_id"); err != nil {
+				return err
+			}
+			q := client.CaseQuery(id)
+			if err := b.sections(
+				sectionSpec{"Existing timeline", "/case/timeline/events/list", q},
+				sectionSpec{"Assets", "/case/assets/list", q},
+				sectionSpec{"IOCs", "/case/ioc/list", q},
+			); err != nil {
+				return err
+			}
+			return b.notes(id)
+		},
+	},
+	{
+		name:        "assets_from_notes",
+		title:       "Find assets in notes",
+		description: "Extract the hosts, accounts and other assets the notes of a DFIR-IRIS case mention and propose those not recorded yet",
+		arg:         "case_id",
+		argDesc:     "ID of the case whose notes to read",
+		options: []option{
+			{name: "asset_type_id", desc: "ID of the asset type to give every proposed asset, instead of choosing one per asset"},
+		},
+		tools: "dfir_iris_assets_list, dfir_iris_notes_groups_list and dfir_iris_notes_get",
+		build: func(ctx context.Context, b *builder, id int) error {
+			assetType := "the best matching asset type from the list below"
+			if t := b.optionID("asset_type_id"); t > 0 {
+				assetType = fmt.Sprintf("asset type %d (see below)", t)
+			}
+			b.instructions(fmt.Sprintf(`List the assets of DFIR-IRIS case %d that its notes, below, mention: hosts, servers, accounts, network devices, cloud resources and the like.
+
+For every asset give its name, IP address and domain when the notes state them, %s, a one-line description of its role in the incident and the notes that mention it.
+
+Leave out the assets already recorded in the case. Present the result as a table, then propose dfir_iris_assets_add calls for the analyst to approve; do not add assets yourself.`, id, assetType))
+			if b.optionID("asset_type_id") > 0 {
+				if err := b.setting("Asset type", "asset_type_id"); err != nil {
+					return err
+				}
+			} else if err := b.section("Asset types", "/manage/asset-type/list", nil); err != nil {
+				return err
+			}
+			if err := b.section("Recorded assets", "/case/assets/list", client.CaseQuery(id)); err != nil {
+				return err
+			}
+			return b.notes(id)
+		},
+	},
+	{
+		name:        "hunt_iocs",
+		title:       "Hunt IOCs in other cases",
+		description: "Search the IOCs of a DFIR-IRIS case across all other cases and assess the links found",
+		arg:         "case_id",
+		argDesc:     "ID of the case whose IOCs to hunt",
+		options: []option{
+			{name: "ioc_type_id", desc: "ID of the IOC type to hunt, e.g. to search only the domains of the case"},
+		},
+		tools: "dfir_iris_iocs_list",
+		build: func(ctx context.Context, b *builder, id int) error {
+			b.instructions(fmt.Sprintf(`Hunt the IOCs of DFIR-IRIS case %d in the other cases, using the search results below.
+
+Ignore matches in case %d itself. For each other case that shares indicators:
+- list the shared IOCs and their types,
+- judge whether the overlap suggests the same campaign or actor, or is likely benign (shared infrastructure, common tools, internal addresses),
+- say what the analyst should check to confirm the link.
+
+End with IOCs that matched no other case, and a short overall assessment. If results were truncated, say which IOCs were not searched.`, id, id))
+			q := client.CaseQuery(id)
+			data, err := b.c.Get(ctx, "/case/ioc/list", q)
+			if err != nil {
+				return err
+			}
+			var iocs model.IOCList
+			if err := json.Unmarshal(data, &iocs); err != nil {
+				return fmt.Errorf("decoding IOCs: %w", err)
+			}
+			if t := b.optionID("ioc_type_id"); t > 0 {
+				if err := b.setting("Hunted IOC type", "ioc_type_id"); err != nil {
+					return err
+				}
+				iocs.IOCs = slices.DeleteFunc(iocs.IOCs, func(ioc model.IOC) bool { return ioc.TypeID != t })
+				b.text(fmt.Sprintf("Only the %d IOCs of this type are hunted; ignore the others in the list below.", len(iocs.IOCs)))
+			}
+			b.raw("IOCs of the case", data)
+			if len(iocs.IOCs) == 0 {
+				b.text("The case has no IOCs to hunt.")
+				return nil
+			}
+			for i, ioc := range iocs.IOCs {
+				if i == maxHuntedIOCs {
+					b.text(fmt.Sprintf("Only the first %d of %d IOCs were searched; use dfir_iris_iocs_list and search the rest as needed.", maxHuntedIOCs, len(iocs.IOCs)))
+					break
+				}
+				if b.full() {
+					b.omit(fmt.Sprintf("the searches for %d more IOCs", min(len(iocs.IOCs), maxHuntedIOCs)-i))
+					break
+				}
+				body := map[string]any{"search_value": ioc.Value, "search_type": "ioc"}
+				data, err := b.c.Post(ctx, "/search", q, body)
+				if err != nil {
+					return fmt.Errorf("searching IOC %q: %w", ioc.Value, err)
+				}
+				b.raw(fmt.Sprintf("Cases with IOC %s (%s)", ioc.Value, ioc.Type), data)
+			}
+			return nil
+		},
+	},
+	{
+		name:        "open_case",
+		title:       "Open case",
+		description: "Draft a new DFIR-IRIS case for a customer from what is known about an incident, optionally from a case template and with a classification",
+		arg:         "customer_id",
+		argDesc:     "ID of the customer the case is for",
+		options: []option{
+			{name: "case_template_id", desc: "ID of the case template to create the case from"},
+			{name: "classification_id", desc: "ID of the case classification, instead of proposing one"},
+			{name: "report", desc: "What is known about the incident so far, e.g. the alert or the reporter's message", text: true},
+		},
+		tools: "dfir_iris_customers_list, dfir_iris_settings_case_templates and dfir_iris_settings_classifications",
+		build: func(ctx context.Context, b *builder, id int) error {
+			report := b.option("report")
+			if report == "" {
+				report = "(No report was given: ask the analyst what happened before drafting the case.)"
+			}
+			b.instructions(fmt.Sprintf(`Draft a new DFIR-IRIS case for customer %d from the incident report below, and the customer, template and classification data that follows it.
+
+Propose:
+1. **Name**: short and specific, naming the kind of incident and the main system or business unit affected.
+2. **Description**: in Markdown, what was reported, by whom and when, the known scope and the open questions.
+3. **SOC ticket ID**: if the report mentions one.
+4. **Classification**: the one given below, or else the best match from the list, with the reason.
+5. **First steps**: if a case template is given, which of its tasks apply first; otherwise the first three tasks to create.
+
+Then propose the dfir_iris_cases_add call, with case_customer %d and the template and classification IDs, for the analyst to approve; do not create the case yourself.
+
+## Incident report
+
+%s`, id, id, report))
+			if err := b.section("Customer", fmt.Sprintf("/manage/customers/%d", id), nil); err != nil {
+				return err
+			}
+			if err := b.setting("Case template", "case_template_id"); err != nil {
+				return err
+			}
+			if b.optionID("classification_id") > 0 {
+				return b.setting("Classification", "classification_id")
+			}
+			return b.section("Classifications", lookup.Sources["classification_id"].Path, nil)
+		},
+	},
+}
+
+// Register adds the workflow prompts to s. Data is read through c and masked
+// with rd, as tool output is, and the data embedded in a prompt is cut at
+// maxBytes in total, if positive.
+func Register(s *mcp.Server, c *client.Client, maxBytes int, rd *redact.Redactor) {
+	for _, p := range prompts {
+		args := []*mcp.PromptArgument{{Name: p.arg, Description: p.argDesc, Required: true}}
+		for _, o := range p.options {
+			args = append(args, &mcp.PromptArgument{Name: o.name, Description: o.desc})
+		}
+		s.AddPrompt(&mcp.Prompt{
+			Name:        p.name,
+			Title:       p.title,
+			Description: p.description,
+			Arguments:   args,
+		}, handler(c, p, maxBytes, rd))
+	}
+}
+
+func handler(c *client.Client, p prompt, maxBytes int, rd *redact.Redactor) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		id, err := strconv.Atoi(req.Params.Arguments[p.arg])
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%s must be a positive integer, got %q", p.arg, req.Params.Arguments[p.arg])
+		}
+		b := &builder{ctx: ctx, c: c, rd: rd, maxBytes: maxBytes, tools: p.tools, args: make(map[string]string)}
+		for _, o := range p.options {
+			v := strings.TrimSpace(req.Params.Arguments[o.name])
+			if v == "" {
+				continue
+			}
+			if n, err := strconv.Atoi(v); !o.text && (err != nil || n <= 0) {
+				return nil, fmt.Errorf("%s must be a positive integer, got %q", o.name, v)
+			}
+			b.args[o.name] = v
+		}
+		if err := p.build(ctx, b, id); err != nil {
+			return nil, errors.New(rd.String(fmt.Sprintf("%s: %v", p.name, err)))
+		}
+		return &mcp.GetPromptResult{
+			Description: p.description,
+			Messages: []*mcp.PromptMessage{{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: b.String()},
+			}},
+		}, nil
+	}
+}
+
+// builder assembles the text of a prompt: instructions followed by
+// sections of IRIS data, up to maxBytes of data in total.
+type builder struct {
+	ctx      context.Context
+	c        *client.Client
+	rd       *redact.Redactor
+	maxBytes int
+	tools    string
+	args     map[string]string // the options given
+	sb       strings.Builder
+	used     int      // bytes of data added
+	omitted  []string // what was left out once maxBytes was used up
+}
+
+type sectionSpec struct {
+	title string
+	path  string
+	query map[string]string
+}
+
+func (b *builder) instructions(s string) {
+	b.sb.WriteString(s)
+	b.sb.WriteString("\n\n# Data\n")
+}
+
+func (b *builder) text(s string) {
+	b.sb.WriteString("\n" + s + "\n")
+}
+
+// option returns the value of the option name, or "" if it was not given.
+func (b *builder) option(name string) string {
+	return b.args[name]
+}
+
+// optionID returns the ID given for the option name, or 0.
+func (b *builder) optionID(name string) int {
+	id, _ := strconv.Atoi(b.args[name])
+	return id
+}
+
+// setting appends the object that the ID option arg refers to, from the
+// list of lookup.Sources that IRIS has for it. It does nothing if the option
+// was not given.
+func (b *builder) setting(title, arg string) error {
+	id := b.optionID(arg)
+	if id == 0 {
+		return nil
+	}
+	if b.full() {
+		b.omit(title)
+		return nil
+	}
+	item, err := lookup.Item(b.ctx, b.c, lookup.Sources[arg], id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.ToLower(title), err)
+	}
+	if item == nil {
+		return fmt.Errorf("%s %d does not exist", arg, id)
+	}
+	b.raw(title, item)
+	return nil
+}
+
+// notes appends every note of the case, by directory, until the size limit
+// is used up.
+func (b *builder) notes(caseID int) error {
+	q := client.CaseQuery(caseID)
+	data, err := b.c.Get(b.ctx, "/case/notes/directories/filter", q)
+	if err != nil {
+		return err
+	}
+	var dirs model.NoteDirectoryList
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		return fmt.Errorf("decoding note directories: %w", err)
+	}
+	total := 0
+	for _, dir := range dirs.Directories {
+		total += len(dir.Notes)
+	}
+	if total == 0 {
+		b.text("The case has no notes.")
+	}
+	n := 0
+	for _, dir := range dirs.Directories {
+		for _, note := range dir.Notes {
+			if b.full() {
+				b.omit(fmt.Sprintf("%d of the %d notes", total-n, total))
+				return nil
+			}
+			title := fmt.Sprintf("Note %d: %s (%s)", note.ID, note.Title, dir.Name)
+			if err := b.section(title, fmt.Sprintf("/case/notes/%d", note.ID), q); err != nil {
+				return err
+			}
+			n++
+		}
+	}
+	return nil
+}
+
+// full reports whether the data of b used up its size limit.
+func (b *builder) full() bool {
+	return b.maxBytes > 0 && b.used >= b.maxBytes
+}
+
+// omit records what was left out of the prompt for lack of room.
+func (b *builder) omit(what string) {
+	b.omitted = append(b.omitted, b.rd.Output(what))
+}
+
+// section appends the IRIS response at path under title, unless the size
+// limit is used up.
+func (b *builder) section(title, path string, query map[string]string) error {
+	if b.full() {
+		b.omit(title)
+		return nil
+	}
+	data, err := b.c.Get(b.ctx, path, query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.ToLower(title), err)
+	}
+	b.raw(title, data)
+	return nil
+}
+
+func (b *builder) sections(specs ...sectionSpec) error {
+	for _, s := range specs {
+		if err := b.section(s.title, s.path, s.query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// raw appends data under title, masked and cut to what remains of the size
+// limit of b.
+func (b *builder) raw(title string, data json.RawMessage) {
+	if b.full() {
+		b.omit(title)
+		return
+	}
+	data = b.rd.OutputData(data)
+	fmt.Fprintf(&b.sb, "\n## %s\n\n```json\n", b.rd.Output(title))
+	if left := b.maxBytes - b.used; b.maxBytes > 0 && len(data) > left {
+		b.used = b.maxBytes
+		fmt.Fprintf(&b.sb, "%s\n```\n\n(Truncated to %d of %d bytes; use the matching tool for the full data.)\n",
+			strings.ToValidUTF8(string(data[:left]), ""), left, len(data))
+		return
+	}
+	b.used += len(data)
+	fmt.Fprintf(&b.sb, "%s\n```\n", data)
+}
+
+func (b *builder) String() string {
+	if len(b.omitted) == 0 {
+		return b.sb.String()
+	}
+	return b.sb.String() + fmt.Sprintf("\n(The data reached the size limit of %d bytes, so this was left out: %s. Use %s for the rest.)\n",
+		b.maxBytes, strings.Join(b.omitted, "; "), b.tools)
+}
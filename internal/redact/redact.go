@@ -0,0 +1,212 @@
+// Package redact masks secrets and personal data in tool arguments, error
+// messages and tool output.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Masked replaces the values of sensitive arguments.
+const Masked = "[REDACTED]"
+
+// DefaultKeys are the substrings of argument names, such as user_password
+// or confirm_token, whose values are masked by default.
+var DefaultKeys = []string{"password", "secret", "token", "api_key", "apikey"}
+
+// Rule masks the text matching Pattern with Replace, which may refer to
+// submatches as in regexp.Regexp.Expand. Rules apply to audit logs and error
+// messages, and to tool output if Outputs is set.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Replace string
+	Outputs bool
+}
+
+// builtins are the rules that can be selected by name.
+var builtins = map[string]Rule{
+	// key=value or key: value pairs such as those pasted in notes.
+	"credential": {
+		Pattern: regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key)(\s*[:=]\s*)("[^"]*"|'[^']*'|\S+)`),
+		Replace: "${1}${2}" + Masked,
+	},
+	"bearer": {
+		Pattern: regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/-]+=*`),
+		Replace: "${1}" + Masked,
+	},
+	"jwt": {
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		Replace: "[REDACTED:jwt]",
+	},
+	"aws_key": {
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`),
+		Replace: "[REDACTED:aws_key]",
+	},
+	"private_key": {
+		Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+		Replace: "[REDACTED:private_key]",
+	},
+	"email": {
+		Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+		Replace: "[REDACTED:email]",
+	},
+}
+
+// Builtin returns the built-in rule called name.
+func Builtin(name string) (Rule, error) {
+	r, ok := builtins[name]
+	if !ok {
+		names := make([]string, 0, len(builtins))
+		for n := range builtins {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		return Rule{}, fmt.Errorf("unknown redaction rule %q (built-in: %s)", name, strings.Join(names, ", "))
+	}
+	r.Name = name
+	return r, nil
+}
+
+// Redactor masks sensitive data. A nil *Redactor masks nothing.
+type Redactor struct {
+	keys    []string // lower case
+	rules   []Rule
+	outputs bool // some rule applies to output
+}
+
+// New returns a Redactor masking the arguments whose names contain one of
+// keys, ignoring case, and the text matching rules.
+func New(keys []string, rules []Rule) *Redactor {
+	r := &Redactor{rules: rules}
+	for _, k := range keys {
+		r.keys = append(r.keys, strings.ToLower(k))
+	}
+	for _, rule := range rules {
+		r.outputs = r.outputs || rule.Outputs
+	}
+	return r
+}
+
+// String masks s with every rule, for logs and error messages.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.Pattern.ReplaceAllString(s, rule.Replace)
+	}
+	return s
+}
+
+// MasksOutput reports whether some rule applies to tool output.
+func (r *Redactor) MasksOutput() bool {
+	return r != nil && r.outputs
+}
+
+// Output masks s with the rules applying to tool output.
+func (r *Redactor) Output(s string) string {
+	if !r.MasksOutput() {
+		return s
+	}
+	for _, rule := range r.rules {
+		if rule.Outputs {
+			s = rule.Pattern.ReplaceAllString(s, rule.Replace)
+		}
+	}
+	return s
+}
+
+// OutputJSON masks the string values of the JSON document data with the
+// rules applying to tool output, leaving its structure intact. It returns
+// data itself if nothing was masked, and false if data is not JSON.
+func (r *Redactor) OutputJSON(data []byte) ([]byte, bool) {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return nil, false
+	}
+	if !r.MasksOutput() {
+		return data, true
+	}
+	masked, changed := r.walk(doc, "", r.Output, false)
+	if !changed {
+		return data, true
+	}
+	out, err := json.Marshal(masked)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// OutputData masks data with the rules applying to tool output, keeping its
+// structure if it is JSON and as text otherwise.
+func (r *Redactor) OutputData(data []byte) []byte {
+	if masked, ok := r.OutputJSON(data); ok {
+		return masked
+	}
+	return []byte(r.Output(string(data)))
+}
+
+// Arguments returns a copy of args in which the values of sensitive
+// arguments, at any depth, are replaced by Masked and strings are masked with
+// every rule.
+func (r *Redactor) Arguments(args map[string]any) map[string]any {
+	if r == nil || args == nil {
+		return args
+	}
+	masked, _ := r.walk(args, "", r.String, true)
+	return masked.(map[string]any)
+}
+
+// walk masks the strings in v, the value of key, with mask and, if byKey is
+// set, the values of sensitive keys with Masked. It reports whether anything
+// changed.
+func (r *Redactor) walk(v any, key string, mask func(string) string, byKey bool) (any, bool) {
+	if byKey && v != nil && r.sensitive(key) {
+		return Masked, true
+	}
+	switch v := v.(type) {
+	case string:
+		m := mask(v)
+		return m, m != v
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		changed := false
+		for k, e := range v {
+			var c bool
+			out[k], c = r.walk(e, k, mask, byKey)
+			changed = changed || c
+		}
+		return out, changed
+	case []any:
+		out := make([]any, len(v))
+		changed := false
+		for i, e := range v {
+			var c bool
+			out[i], c = r.walk(e, key, mask, byKey)
+			changed = changed || c
+		}
+		return out, changed
+	}
+	return v, false
+}
+
+func (r *Redactor) sensitive(key string) bool {
+	if key == "" {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
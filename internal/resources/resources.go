@@ -0,0 +1,129 @@
+// Package resources exposes IRIS cases, notes, timelines, IOCs and customers
+// as MCP resources, so that clients can attach them as context without the model
+// spending a tool call.
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/redact"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Scheme prefixes every resource URI.
+const Scheme = "iris://"
+
+// template is a resource template and the IRIS request that reads it. The
+// variables of uri are all integer IDs.
+type template struct {
+	name        string
+	uri         string
+	description string
+	read        func(ctx context.Context, c *client.Client, ids map[string]int) (json.RawMessage, error)
+}
+
+var templates = []template{
+	{
+		name:        "case",
+		uri:         Scheme + "cases/{case_id}",
+		description: "Details of a DFIR-IRIS case: name, description, customer, state, owner and dates",
+		read: func(ctx context.Context, c *client.Client, ids map[string]int) (json.RawMessage, error) {
+			return c.Get(ctx, fmt.Sprintf("/manage/cases/%d", ids["case_id"]), nil)
+		},
+	},
+	{
+		name:        "case-note",
+		uri:         Scheme + "cases/{case_id}/notes/{note_id}",
+		description: "A note of a DFIR-IRIS case, with its title and Markdown content",
+		read: func(ctx context.Context, c *client.Client, ids map[string]int) (json.RawMessage, error) {
+			return c.Get(ctx, fmt.Sprintf("/case/notes/%d", ids["note_id"]), client.CaseQuery(ids["case_id"]))
+		},
+	},
+	{
+		name:        "case-timeline",
+		uri:         Scheme + "cases/{case_id}/timeline",
+		description: "The timeline events of a DFIR-IRIS case",
+		read: func(ctx context.Context, c *client.Client, ids map[string]int) (json.RawMessage, error) {
+			return c.Get(ctx, "/case/timeline/events/list", client.CaseQuery(ids["case_id"]))
+		},
+	},
+	{
+		name:        "case-iocs",
+		uri:         Scheme + "cases/{case_id}/iocs",
+		description: "The indicators of compromise of a DFIR-IRIS case",
+		read: func(ctx context.Context, c *client.Client, ids map[string]int) (json.RawMessage, error) {
+			return c.Get(ctx, "/case/ioc/list", client.CaseQuery(ids["case_id"]))
+		},
+	},
+	{
+		name:        "customer",
+		uri:         Scheme + "customers/{customer_id}",
+		description: "Details of a DFIR-IRIS customer: name, description and SLA",
+		read: func(ctx context.Context, c *client.Client, ids map[string]int) (json.RawMessage, error) {
+			return c.Get(ctx, fmt.Sprintf("/manage/customers/%d", ids["customer_id"]), nil)
+		},
+	},
+}
+
+// Register adds the IRIS resource templates to s, read through c. Their
+// contents and errors are masked with rd, as tool output is.
+func Register(s *mcp.Server, c *client.Client, rd *redact.Redactor) {
+	for _, t := range templates {
+		s.AddResourceTemplate(&mcp.ResourceTemplate{
+			Name:        t.name,
+			URITemplate: t.uri,
+			Description: t.description,
+			MIMEType:    "application/json",
+		}, handler(c, t, rd))
+	}
+}
+
+func handler(c *client.Client, t template, rd *redact.Redactor) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		ids, ok := match(t.uri, uri)
+		if !ok {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		data, err := t.read(ctx, c, ids)
+		if err != nil {
+			if client.Kind(err) == client.KindNotFound {
+				return nil, mcp.ResourceNotFoundError(uri)
+			}
+			return nil, errors.New(rd.String(fmt.Sprintf("reading %s: %v", uri, err)))
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(rd.OutputData(data))}},
+		}, nil
+	}
+}
+
+// match extracts the IDs of uri according to tmpl, whose variables are the
+// path segments written {name}.
+func match(tmpl, uri string) (map[string]int, bool) {
+	want := strings.Split(strings.TrimPrefix(tmpl, Scheme), "/")
+	got := strings.Split(strings.TrimPrefix(uri, Scheme), "/")
+	if !strings.HasPrefix(uri, Scheme) || len(got) != len(want) {
+		return nil, false
+	}
+	ids := make(map[string]int)
+	for i, seg := range want {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			id, err := strconv.Atoi(got[i])
+			if err != nil || id <= 0 {
+				return nil, false
+			}
+			ids[strings.TrimSuffix(name, "}")] = id
+		} else if got[i] != seg {
+			return nil, false
+		}
+	}
+	return ids, true
+}
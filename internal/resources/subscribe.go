@@ -0,0 +1,386 @@
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Watcher tracks resource subscriptions and polls the subscribed cases for
+// changes, notifying subscribers with resources/updated.
+//
+// Any resource of a case subscribes to the whole case: the case, its IOCs,
+// timeline, tasks and notes are snapshotted on every poll. A change notifies
+// the case URI and, for IOCs, the timeline and notes, the URI of the part
+// that changed. The first poll of a case only records its baseline.
+//
+// A case is polled once per distinct API key among its subscribers, and a
+// change only notifies the sessions subscribed with the key that saw it, so
+// that a session never learns of changes to a case it can no longer read.
+type Watcher struct {
+	c        *client.Client
+	interval time.Duration
+	max      int
+	log      *slog.Logger
+
+	mu     sync.Mutex
+	subs   map[string]map[*mcp.ServerSession]subscription // by URI
+	cases  map[view]*snapshot
+	notify map[string]map[*mcp.ServerSession]bool // by URI, while notifying
+}
+
+// subscription is the subscription of a session to a URI.
+type subscription struct {
+	ctx        context.Context
+	credential string // see client.Client.Credential
+}
+
+// view is a case as seen with one credential.
+type view struct {
+	caseID     int
+	credential string
+}
+
+// snapshot holds a fingerprint of each part of a case, by part name.
+type snapshot struct {
+	parts map[string]string
+}
+
+// NewWatcher returns a Watcher that polls c every interval and accepts at
+// most max subscriptions across all sessions, or any number if max is zero.
+// Polling failures are logged to log.
+func NewWatcher(c *client.Client, interval time.Duration, max int, log *slog.Logger) *Watcher {
+	return &Watcher{
+		c:        c,
+		interval: interval,
+		max:      max,
+		log:      log,
+		subs:     make(map[string]map[*mcp.ServerSession]subscription),
+		cases:    make(map[view]*snapshot),
+	}
+}
+
+// Subscribe is the mcp.ServerOptions.SubscribeHandler of w.
+func (w *Watcher) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	if _, ok := caseOf(uri); !ok {
+		for _, t := range templates {
+			if _, ok := match(t.uri, uri); ok {
+				return fmt.Errorf("%s cannot be subscribed to: only the resources of a case are watched for changes", uri)
+			}
+		}
+		return mcp.ResourceNotFoundError(uri)
+	}
+	credential, err := w.c.Credential(ctx)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.subs[uri][req.Session]; ok {
+		return nil
+	}
+	if w.max > 0 && w.count() >= w.max {
+		return fmt.Errorf("too many resource subscriptions (limit %d): unsubscribe from another resource first", w.max)
+	}
+	if w.subs[uri] == nil {
+		w.subs[uri] = make(map[*mcp.ServerSession]subscription)
+	}
+	// Keep the request values, such as the session API key, beyond the
+	// request, and bypass the response cache, which could hide changes.
+	w.subs[uri][req.Session] = subscription{
+		ctx:        client.WithoutCache(context.WithoutCancel(ctx)),
+		credential: credential,
+	}
+	return nil
+}
+
+// Unsubscribe is the mcp.ServerOptions.UnsubscribeHandler of w.
+func (w *Watcher) Unsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.remove(req.Params.URI, req.Session)
+	return nil
+}
+
+// Run polls the subscribed cases every interval of w and notifies the
+// subscribers of s, until ctx is done.
+func (w *Watcher) Run(ctx context.Context, s *mcp.Server) {
+	s.AddSendingMiddleware(w.filterUpdates)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.prune(s)
+		for v, subCtx := range w.watched() {
+			for _, uri := range w.poll(subCtx, v) {
+				w.updated(ctx, s, uri, v.credential)
+			}
+		}
+	}
+}
+
+// updated notifies the sessions subscribed to uri with credential that the
+// resource changed. The server notifies every subscriber of uri, so the
+// others are left out by filterUpdates.
+func (w *Watcher) updated(ctx context.Context, s *mcp.Server, uri, credential string) {
+	w.mu.Lock()
+	sessions := make(map[*mcp.ServerSession]bool)
+	for ss, sub := range w.subs[uri] {
+		if sub.credential == credential {
+			sessions[ss] = true
+		}
+	}
+	if len(sessions) == 0 {
+		w.mu.Unlock()
+		return
+	}
+	w.notify = map[string]map[*mcp.ServerSession]bool{uri: sessions}
+	w.mu.Unlock()
+
+	_ = s.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+
+	w.mu.Lock()
+	w.notify = nil
+	w.mu.Unlock()
+}
+
+// filterUpdates is a sending middleware dropping the resources/updated
+// notifications of sessions that updated did not select.
+func (w *Watcher) filterUpdates(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.ResourceUpdatedNotificationParams)
+		if !ok || method != "notifications/resources/updated" {
+			return next(ctx, method, req)
+		}
+		ss, _ := req.GetSession().(*mcp.ServerSession)
+		w.mu.Lock()
+		selected := w.notify[params.URI][ss]
+		w.mu.Unlock()
+		if !selected {
+			return nil, nil
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// count returns the number of subscriptions. w.mu must be held.
+func (w *Watcher) count() int {
+	n := 0
+	for _, sessions := range w.subs {
+		n += len(sessions)
+	}
+	return n
+}
+
+// remove drops the subscription of ss to uri, and the snapshots of its case
+// that no subscription uses any more. w.mu must be held.
+func (w *Watcher) remove(uri string, ss *mcp.ServerSession) {
+	sub, ok := w.subs[uri][ss]
+	if !ok {
+		return
+	}
+	delete(w.subs[uri], ss)
+	if len(w.subs[uri]) == 0 {
+		delete(w.subs, uri)
+	}
+	caseID, _ := caseOf(uri)
+	v := view{caseID: caseID, credential: sub.credential}
+	if !w.subscribed(v) {
+		delete(w.cases, v)
+	}
+}
+
+// prune drops the subscriptions of sessions that closed without
+// unsubscribing, which the server forgets without telling w.
+func (w *Watcher) prune(s *mcp.Server) {
+	live := make(map[*mcp.ServerSession]bool)
+	for ss := range s.Sessions() {
+		live[ss] = true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for uri, sessions := range w.subs {
+		for ss := range sessions {
+			if !live[ss] {
+				w.remove(uri, ss)
+			}
+		}
+	}
+}
+
+// watched returns the subscribed cases, once per credential subscribed to
+// them, each with the context of one of its subscriptions to poll it with.
+func (w *Watcher) watched() map[view]context.Context {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	views := make(map[view]context.Context)
+	for _, uri := range slices.Sorted(maps.Keys(w.subs)) {
+		caseID, _ := caseOf(uri)
+		for _, sub := range w.subs[uri] {
+			v := view{caseID: caseID, credential: sub.credential}
+			if views[v] == nil {
+				views[v] = sub.ctx
+			}
+		}
+	}
+	return views
+}
+
+// poll snapshots the case of v with its credential and returns the URIs to
+// notify of the changes since the previous snapshot. A case the credential
+// cannot read any more notifies nothing.
+func (w *Watcher) poll(ctx context.Context, v view) []string {
+	caseID := v.caseID
+	parts, err := w.snapshot(ctx, caseID)
+	if err != nil {
+		w.log.WarnContext(ctx, "polling subscribed case", "case_id", caseID, "error", err)
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev, ok := w.cases[v]
+	if !ok {
+		// Unsubscribed while polling, or nothing to compare with yet.
+		if w.subscribed(v) {
+			w.cases[v] = &snapshot{parts: parts}
+		}
+		return nil
+	}
+	var changed []string
+	for name := range parts {
+		if parts[name] != prev.parts[name] {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prev.parts {
+		if _, ok := parts[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	prev.parts = parts
+	if len(changed) == 0 {
+		return nil
+	}
+
+	base := fmt.Sprintf("%scases/%d", Scheme, caseID)
+	uris := []string{base}
+	for _, name := range changed {
+		if name == "iocs" || name == "timeline" {
+			uris = append(uris, base+"/"+name)
+		} else if noteID, ok := notePart(name); ok {
+			uris = append(uris, fmt.Sprintf("%s/notes/%d", base, noteID))
+		}
+	}
+	slices.Sort(uris[1:])
+	return slices.Compact(uris)
+}
+
+// subscribed reports whether any resource of the case of v is subscribed
+// with its credential. w.mu must be held.
+func (w *Watcher) subscribed(v view) bool {
+	for uri, sessions := range w.subs {
+		if id, _ := caseOf(uri); id != v.caseID {
+			continue
+		}
+		for _, sub := range sessions {
+			if sub.credential == v.credential {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// snapshot fingerprints the case, its IOCs, timeline, tasks, note
+// directories and each of its notes.
+func (w *Watcher) snapshot(ctx context.Context, caseID int) (map[string]string, error) {
+	q := client.CaseQuery(caseID)
+	parts := make(map[string]string)
+	for _, p := range []struct{ name, path string }{
+		{"case", fmt.Sprintf("/manage/cases/%d", caseID)},
+		{"iocs", "/case/ioc/list"},
+		{"timeline", "/case/timeline/events/list"},
+		{"tasks", "/case/tasks/list"},
+		{"notes", "/case/notes/directories/filter"},
+	} {
+		data, err := w.c.Get(ctx, p.path, q)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.name, err)
+		}
+		parts[p.name] = fingerprint(data)
+		if p.name != "notes" {
+			continue
+		}
+		var dirs model.NoteDirectoryList
+		if err := json.Unmarshal(data, &dirs); err != nil {
+			return nil, fmt.Errorf("decoding note directories: %w", err)
+		}
+		for _, dir := range dirs.Directories {
+			for _, note := range dir.Notes {
+				data, err := w.c.Get(ctx, fmt.Sprintf("/case/notes/%d", note.ID), q)
+				if err != nil {
+					return nil, fmt.Errorf("note %d: %w", note.ID, err)
+				}
+				parts[fmt.Sprintf("note/%d", note.ID)] = fingerprint(data)
+			}
+		}
+	}
+	return parts, nil
+}
+
+// fingerprint identifies a version of an IRIS response. It uses the
+// modification state IRIS attaches to case object lists, or the last update
+// of a note, when present, and otherwise a hash of the response.
+func fingerprint(data json.RawMessage) string {
+	var v struct {
+		State *struct {
+			ObjectState      any `json:"object_state"`
+			ObjectLastUpdate any `json:"object_last_update"`
+		} `json:"state"`
+		NoteLastUpdate string `json:"note_lastupdate"`
+	}
+	if json.Unmarshal(data, &v) == nil {
+		if v.State != nil && (v.State.ObjectState != nil || v.State.ObjectLastUpdate != nil) {
+			return fmt.Sprintf("state:%v@%v", v.State.ObjectState, v.State.ObjectLastUpdate)
+		}
+		if v.NoteLastUpdate != "" {
+			return "updated:" + v.NoteLastUpdate
+		}
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// caseOf returns the case of a resource URI, if it is a resource of a case.
+func caseOf(uri string) (int, bool) {
+	for _, t := range templates {
+		if ids, ok := match(t.uri, uri); ok {
+			id, ok := ids["case_id"]
+			return id, ok
+		}
+	}
+	return 0, false
+}
+
+func notePart(name string) (int, bool) {
+	var id int
+	_, err := fmt.Sscanf(name, "note/%d", &id)
+	return id, err == nil
+}
@@ -0,0 +1,106 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestWatcherSharesPolls(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		version  = 1
+		requests = make(map[string]int) // case fetches by API key
+	)
+	iris := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		data := "[]"
+		if r.URL.Path == "/manage/cases/5" {
+			requests[r.Header.Get("Authorization")]++
+			data = fmt.Sprintf(`{"case_id":5,"version":%d}`, version)
+		}
+		fmt.Fprintf(w, `{"status":"success","data":%s}`, data)
+	}))
+	defer iris.Close()
+
+	w := NewWatcher(client.New(iris.URL, "shared"), time.Minute, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	other := client.WithAPIKey(ctx, "other")
+	a, b, c := new(mcp.ServerSession), new(mcp.ServerSession), new(mcp.ServerSession)
+	for _, s := range []struct {
+		ctx context.Context
+		ss  *mcp.ServerSession
+		uri string
+	}{
+		{ctx, a, "iris://cases/5"},
+		{ctx, a, "iris://cases/5/iocs"},
+		{ctx, b, "iris://cases/5"},
+		{ctx, b, "iris://cases/5/notes/3"},
+		{other, c, "iris://cases/5/timeline"},
+	} {
+		if err := w.Subscribe(s.ctx, &mcp.SubscribeRequest{Session: s.ss, Params: &mcp.SubscribeParams{URI: s.uri}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// One poll of the case per key, whatever the sessions and URIs.
+	pollAll := func() map[string][]string {
+		t.Helper()
+		views := w.watched()
+		if len(views) != 2 {
+			t.Fatalf("watching %d views of the case, want 2 (one per key)", len(views))
+		}
+		changed := make(map[string][]string)
+		for v, subCtx := range views {
+			if uris := w.poll(subCtx, v); uris != nil {
+				changed[v.credential] = uris
+			}
+		}
+		return changed
+	}
+	if changed := pollAll(); len(changed) != 0 {
+		t.Errorf("baseline poll reported changes: %v", changed)
+	}
+	mu.Lock()
+	version++
+	mu.Unlock()
+	changed := pollAll()
+	want := []string{"iris://cases/5"}
+	if len(changed) != 2 || !reflect.DeepEqual(changed[credentialOf(t, w, ctx)], want) || !reflect.DeepEqual(changed[credentialOf(t, w, other)], want) {
+		t.Errorf("changes = %v, want %v for each key", changed, want)
+	}
+	mu.Lock()
+	if want := map[string]int{"Bearer shared": 2, "Bearer other": 2}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("case fetches by key = %v, want %v", requests, want)
+	}
+	mu.Unlock()
+
+	_ = w.Unsubscribe(ctx, &mcp.UnsubscribeRequest{Session: c, Params: &mcp.UnsubscribeParams{URI: "iris://cases/5/timeline"}})
+	if views := w.watched(); len(views) != 1 {
+		t.Errorf("watching %d views after the other key unsubscribed, want 1", len(views))
+	}
+	if len(w.cases) != 1 {
+		t.Errorf("%d snapshots kept, want 1", len(w.cases))
+	}
+}
+
+func credentialOf(t *testing.T, w *Watcher, ctx context.Context) string {
+	t.Helper()
+	cred, err := w.c.Credential(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cred
+}
@@ -0,0 +1,129 @@
+// Package telemetry exports Prometheus metrics and OpenTelemetry traces of
+// tool calls and IRIS requests.
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dfir_iris_mcp"
+
+// Metrics holds the Prometheus metrics of the server in its own registry.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	toolCalls       *prometheus.CounterVec
+	toolErrors      *prometheus.CounterVec
+	toolDuration    *prometheus.HistogramVec
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+// NewMetrics returns the metrics of the server, along with the Go runtime
+// and process metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tool_calls_total",
+			Help:      "Tool calls, by tool.",
+		}, []string{"tool"}),
+		toolErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tool_errors_total",
+			Help:      "Failed tool calls, by tool, error kind and HTTP status from IRIS (empty if none).",
+		}, []string{"tool", "kind", "status"}),
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tool_call_duration_seconds",
+			Help:      "Duration of tool calls, by tool.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"tool"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "iris_requests_total",
+			Help:      "Requests sent to IRIS, by instance, method, route and HTTP status (0 if IRIS did not answer).",
+		}, []string{"instance", "method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "iris_request_duration_seconds",
+			Help:      "Duration of requests to IRIS, including retries, by instance, method and route.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"instance", "method", "route"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.toolCalls, m.toolErrors, m.toolDuration, m.requests, m.requestDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// ToolCall records a call of tool that took d. kind is the kind of its
+// error, empty on success, and status the HTTP status of the IRIS error, if
+// any.
+func (m *Metrics) ToolCall(tool string, d time.Duration, kind client.ErrorKind, status int) {
+	m.toolCalls.WithLabelValues(tool).Inc()
+	m.toolDuration.WithLabelValues(tool).Observe(d.Seconds())
+	if kind != "" {
+		s := ""
+		if status != 0 {
+			s = strconv.Itoa(status)
+		}
+		m.toolErrors.WithLabelValues(tool, string(kind), s).Inc()
+	}
+}
+
+// RequestObserver returns the observer recording the requests of the client
+// of instance, for client.WithRequestObserver.
+func (m *Metrics) RequestObserver(instance string) func(client.Request) {
+	return func(r client.Request) {
+		m.requests.WithLabelValues(instance, r.Method, r.Route, strconv.Itoa(r.Status)).Inc()
+		m.requestDuration.WithLabelValues(instance, r.Method, r.Route).Observe(r.Duration.Seconds())
+	}
+}
+
+// WatchCaches exports the hits, misses and size of the caches of c, the
+// client of instance.
+func (m *Metrics) WatchCaches(instance string, c *client.Client) {
+	for name, stats := range map[string]func() client.CacheStats{
+		"settings":  c.SettingsStats,
+		"responses": c.ResponseCacheStats,
+	} {
+		labels := prometheus.Labels{"instance": instance, "cache": name}
+		m.Registry.MustRegister(
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "cache_hits_total",
+				Help:        "Responses served from a cache, by instance and cache.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(stats().Hits) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "cache_misses_total",
+				Help:        "Lookups a cache could not serve, by instance and cache.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(stats().Misses) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "cache_entries",
+				Help:        "Entries held by a cache, by instance and cache.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(stats().Entries) }),
+		)
+	}
+}
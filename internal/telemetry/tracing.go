@@ -0,0 +1,43 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// StartTracing installs the global tracer provider exporting spans with
+// exporter: "otlp" sends them over OTLP/HTTP to the collector set by the
+// standard OTEL_EXPORTER_OTLP_* variables. The returned function flushes
+// and stops the export.
+func StartTracing(ctx context.Context, exporter, version string) (func(context.Context) error, error) {
+	var exp sdktrace.SpanExporter
+	switch exporter {
+	case "otlp":
+		var err error
+		if exp, err = otlptracehttp.New(ctx); err != nil {
+			return nil, fmt.Errorf("tracing: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q (want otlp)", exporter)
+	}
+	tp := newTracerProvider(sdktrace.WithBatcher(exp), version)
+	return tp.Shutdown, nil
+}
+
+func newTracerProvider(export sdktrace.TracerProviderOption, version string) *sdktrace.TracerProvider {
+	res, _ := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("dfir-iris-mcp"),
+		semconv.ServiceVersion(version),
+	))
+	tp := sdktrace.NewTracerProvider(export, sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp
+}
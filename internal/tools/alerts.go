@@ -5,11 +5,12 @@ import (
 	"fmt"
 
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerAlerts(s *mcp.Server, c *client.Client) {
+func registerAlerts(ts *toolset, c *client.Client) {
 	// Filter alerts
 	type alertsFilterArgs struct {
 		AlertTitle          *string `json:"alert_title,omitempty" jsonschema:"Filter by alert title"`
@@ -21,33 +22,35 @@ func registerAlerts(s *mcp.Server, c *client.Client) {
 		Page                *int    `json:"page,omitempty" jsonschema:"Page number"`
 		PerPage             *int    `json:"per_page,omitempty" jsonschema:"Results per page"`
 		Sort                *string `json:"sort,omitempty" jsonschema:"Sort field"`
+		Cursor              string  `json:"cursor,omitempty" jsonschema:"next_cursor from a previous call, to get the following results; the other arguments are then taken from the cursor"`
+		FetchAll            bool    `json:"fetch_all,omitempty" jsonschema:"Walk all pages server-side, up to the server's item limit; next_cursor is set if it was reached"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_alerts_filter",
-		Description: "Filter alerts with optional search criteria",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsFilterArgs) (*mcp.CallToolResult, any, error) {
-		q := toQuery(args)
-		data, err := c.Get(ctx, "/alerts/filter", q)
+		Description: "Filter alerts with optional search criteria. Results are paginated: pass next_cursor as cursor to continue, or set fetch_all",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsFilterArgs) (*mcp.CallToolResult, *model.AlertPage, error) {
+		q := toQuery(args, "cursor", "fetch_all")
+		data, err := filterPages(ctx, c, "/alerts/filter", "alerts", q, args.Cursor, args.FetchAll, ts.opts.FetchAllLimit)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.AlertPage](data)
 	})
 
 	// Get a single alert
 	type alertsGetArgs struct {
 		AlertID int `json:"alert_id" jsonschema:"Alert ID to retrieve"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_alerts_get",
 		Description: "Get details of a specific alert",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsGetArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsGetArgs) (*mcp.CallToolResult, *model.Alert, error) {
 		path := fmt.Sprintf("/alerts/%d", args.AlertID)
 		data, err := c.Get(ctx, path, nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Alert](data)
 	})
 
 	// Add an alert
@@ -64,15 +67,15 @@ func registerAlerts(s *mcp.Server, c *client.Client) {
 		AlertNote           *string `json:"alert_note,omitempty" jsonschema:"Alert note"`
 		AlertTags           *string `json:"alert_tags,omitempty" jsonschema:"Comma-separated tags"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_alerts_add",
 		Description: "Create a new alert",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsAddArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsAddArgs) (*mcp.CallToolResult, *model.Alert, error) {
 		data, err := c.Post(ctx, "/alerts/add", nil, toBody(args))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Alert](data)
 	})
 
 	// Update an alert
@@ -88,26 +91,35 @@ func registerAlerts(s *mcp.Server, c *client.Client) {
 		AlertNote           *string `json:"alert_note,omitempty" jsonschema:"New note"`
 		AlertTags           *string `json:"alert_tags,omitempty" jsonschema:"New comma-separated tags"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_alerts_update",
 		Description: "Update an existing alert",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsUpdateArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsUpdateArgs) (*mcp.CallToolResult, *model.Alert, error) {
 		path := fmt.Sprintf("/alerts/update/%d", args.AlertID)
 		data, err := c.Post(ctx, path, nil, toBody(args, "alert_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Alert](data)
 	})
 
 	// Delete an alert
 	type alertsDeleteArgs struct {
-		AlertID int `json:"alert_id" jsonschema:"ID of the alert to delete"`
+		AlertID      int     `json:"alert_id" jsonschema:"ID of the alert to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_alerts_delete",
-		Description: "Delete an alert (irreversible)",
+		Description: "Delete an alert (irreversible, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of alert %d", args.AlertID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return fmt.Sprintf("Alert %d will be permanently deleted.\n%s", args.AlertID,
+				describeObject(ctx, c, fmt.Sprintf("/alerts/%d", args.AlertID), nil,
+					"alert_title", "alert_source", "alert_source_ref", "alert_creation_time"))
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/alerts/delete/%d", args.AlertID)
 		data, err := c.Post(ctx, path, nil, nil)
 		if err != nil {
@@ -124,7 +136,7 @@ func registerAlerts(s *mcp.Server, c *client.Client) {
 		CaseID         *int  `json:"case_id,omitempty" jsonschema:"Existing case ID to escalate into (creates new case if omitted)"`
 		CaseTemplateID *int  `json:"case_template_id,omitempty" jsonschema:"Case template ID for the new case"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_alerts_escalate",
 		Description: "Escalate an alert to a new or existing case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsEscalateArgs) (*mcp.CallToolResult, any, error) {
@@ -141,7 +153,7 @@ func registerAlerts(s *mcp.Server, c *client.Client) {
 		AlertID      int `json:"alert_id" jsonschema:"ID of the alert to merge"`
 		TargetCaseID int `json:"target_case_id" jsonschema:"Case ID to merge the alert into"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_alerts_merge",
 		Description: "Merge an alert into an existing case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsMergeArgs) (*mcp.CallToolResult, any, error) {
@@ -158,7 +170,7 @@ func registerAlerts(s *mcp.Server, c *client.Client) {
 	type alertsUnmergeArgs struct {
 		AlertID int `json:"alert_id" jsonschema:"ID of the alert to unmerge from its case"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_alerts_unmerge",
 		Description: "Unmerge an alert from its associated case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertsUnmergeArgs) (*mcp.CallToolResult, any, error) {
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"dfir-iris-mcp/internal/audit"
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxAuditIDs caps the IDs recorded per field, so that auditing a large list
+// does not copy it into the log.
+const maxAuditIDs = 100
+
+// audited wraps h so that every call of the tool is written to the audit
+// log, if there is one. A failure to write is logged but does not fail the
+// call, whose effect on IRIS has already happened.
+func audited[In any](ts *toolset, name string, h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	logger := ts.opts.Audit
+	if logger == nil {
+		return h
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		var (
+			mu   sync.Mutex
+			reqs []audit.Request
+		)
+		ctx = client.WithObserver(ctx, func(r client.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			reqs = append(reqs, audit.Request{
+				Method:     r.Method,
+				Path:       r.Path,
+				Status:     r.Status,
+				DurationMS: r.Duration.Milliseconds(),
+				Retries:    r.Retries,
+			})
+		})
+		res, out, err := h(ctx, req, in)
+
+		var args map[string]any
+		_ = json.Unmarshal(req.Params.Arguments, &args)
+		rec := audit.Record{
+			Time:       start.UTC(),
+			Tool:       name,
+			Instance:   ts.opts.Primary,
+			Arguments:  args,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if req.Session != nil {
+			rec.Session = req.Session.ID()
+		}
+		if req.Extra != nil && req.Extra.TokenInfo != nil {
+			rec.User = req.Extra.TokenInfo.UserID
+		}
+		if inst, ok := args["instance"].(string); ok && inst != "" {
+			rec.Instance = inst
+		}
+		mu.Lock()
+		rec.Requests = reqs
+		mu.Unlock()
+		switch {
+		case err != nil:
+			rec.Error = &audit.Error{Kind: string(client.Kind(err)), Message: err.Error()}
+		case res != nil && res.IsError:
+			rec.Error = &audit.Error{Kind: string(client.KindUnknown)}
+			if te, ok := toolErrorOf(res); ok {
+				rec.Error.Kind, rec.Error.Message = string(te.Kind), te.Message
+			}
+		default:
+			rec.ObjectIDs = resultIDs(res, out)
+		}
+		if werr := logger.Write(rec); werr != nil {
+			logging.FromContext(ctx, ts.opts.Logger).ErrorContext(ctx, "writing audit record", "error", werr)
+		}
+		return res, out, err
+	}
+}
+
+// objectIDFields are the fields identifying IRIS objects, as opposed to
+// settings such as ioc_type_id, in tool results.
+var objectIDFields = map[string]bool{
+	"case_id": true, "alert_id": true, "customer_id": true, "user_id": true,
+	"group_id": true, "asset_id": true, "ioc_id": true, "event_id": true,
+	"task_id": true, "evidence_id": true, "note_id": true, "comment_id": true,
+	"file_id": true,
+}
+
+// resultIDs collects the IDs of the objects in a tool result: the
+// objectIDFields of the result object, of each item if it is a list, and of
+// the items of its list fields, such as {"ioc": [...]}, or of its data, such
+// as {"total": 3, "data": [...]}.
+func resultIDs(res *mcp.CallToolResult, out any) map[string][]int64 {
+	var data []byte
+	if res != nil {
+		for _, c := range res.Content {
+			if t, ok := c.(*mcp.TextContent); ok {
+				data = []byte(t.Text)
+				break
+			}
+		}
+	}
+	var v any
+	if json.Unmarshal(data, &v) != nil {
+		// Truncated or plain text output; fall back to the structured one.
+		b, err := json.Marshal(out)
+		if out == nil || err != nil || json.Unmarshal(b, &v) != nil {
+			return nil
+		}
+	}
+	ids := make(map[string][]int64)
+	var collect func(v any, nested bool)
+	collect = func(v any, nested bool) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				collect(item, nested)
+			}
+		case map[string]any:
+			for k, field := range v {
+				n, ok := field.(float64)
+				if ok && objectIDFields[k] && n == float64(int64(n)) {
+					if !slices.Contains(ids[k], int64(n)) && len(ids[k]) < maxAuditIDs {
+						ids[k] = append(ids[k], int64(n))
+					}
+				}
+				if _, isList := field.([]any); !nested && (isList || k == "data") {
+					collect(field, true)
+				}
+			}
+		}
+	}
+	collect(v, false)
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
@@ -5,21 +5,22 @@ import (
 	"fmt"
 
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerCases(s *mcp.Server, c *client.Client) {
+func registerCases(ts *toolset, c *client.Client) {
 	// List all cases
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_cases_list",
 		Description: "List all cases in DFIR-IRIS",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *model.CaseList, error) {
 		data, err := c.Get(ctx, "/manage/cases/list", nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.CaseList](data)
 	})
 
 	// Filter cases
@@ -30,17 +31,19 @@ func registerCases(s *mcp.Server, c *client.Client) {
 		Page         *int    `json:"page,omitempty" jsonschema:"Page number for pagination"`
 		PerPage      *int    `json:"per_page,omitempty" jsonschema:"Results per page"`
 		Sort         *string `json:"sort,omitempty" jsonschema:"Sort field"`
+		Cursor       string  `json:"cursor,omitempty" jsonschema:"next_cursor from a previous call, to get the following results; the other arguments are then taken from the cursor"`
+		FetchAll     bool    `json:"fetch_all,omitempty" jsonschema:"Walk all pages server-side, up to the server's item limit; next_cursor is set if it was reached"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_cases_filter",
-		Description: "Filter cases with optional search criteria",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesFilterArgs) (*mcp.CallToolResult, any, error) {
-		q := toQuery(args)
-		data, err := c.Get(ctx, "/manage/cases/filter", q)
+		Description: "Filter cases with optional search criteria. Results are paginated: pass next_cursor as cursor to continue, or set fetch_all",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesFilterArgs) (*mcp.CallToolResult, *model.CasePage, error) {
+		q := toQuery(args, "cursor", "fetch_all")
+		data, err := filterPages(ctx, c, "/manage/cases/filter", "cases", q, args.Cursor, args.FetchAll, ts.opts.FetchAllLimit)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.CasePage](data)
 	})
 
 	// Add a case
@@ -52,15 +55,15 @@ func registerCases(s *mcp.Server, c *client.Client) {
 		ClassificationID *int  `json:"classification_id,omitempty" jsonschema:"Classification ID"`
 		CaseTemplateID *int    `json:"case_template_id,omitempty" jsonschema:"Case template ID to apply"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_cases_add",
 		Description: "Create a new case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesAddArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesAddArgs) (*mcp.CallToolResult, *model.Case, error) {
 		data, err := c.Post(ctx, "/manage/cases/add", nil, toBody(args))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Case](data)
 	})
 
 	// Update a case
@@ -73,26 +76,33 @@ func registerCases(s *mcp.Server, c *client.Client) {
 		ClassificationID *int   `json:"classification_id,omitempty" jsonschema:"New classification ID"`
 		StateID         *int    `json:"state_id,omitempty" jsonschema:"New state ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_cases_update",
 		Description: "Update an existing case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesUpdateArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesUpdateArgs) (*mcp.CallToolResult, *model.Case, error) {
 		path := fmt.Sprintf("/manage/cases/update/%d", args.CaseID)
 		data, err := c.Post(ctx, path, nil, toBody(args, "case_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Case](data)
 	})
 
 	// Delete a case
 	type casesDeleteArgs struct {
-		CaseID int `json:"case_id" jsonschema:"ID of the case to delete"`
+		CaseID       int     `json:"case_id" jsonschema:"ID of the case to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_cases_delete",
-		Description: "Delete a case (irreversible)",
+		Description: "Delete a case (irreversible, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of case %d", args.CaseID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return describeCase(ctx, c, args.CaseID)
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/manage/cases/delete/%d", args.CaseID)
 		data, err := c.Post(ctx, path, nil, nil)
 		if err != nil {
@@ -105,7 +115,7 @@ func registerCases(s *mcp.Server, c *client.Client) {
 	type casesCloseArgs struct {
 		CaseID int `json:"case_id" jsonschema:"ID of the case to close"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_cases_close",
 		Description: "Close a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesCloseArgs) (*mcp.CallToolResult, any, error) {
@@ -121,7 +131,7 @@ func registerCases(s *mcp.Server, c *client.Client) {
 	type casesReopenArgs struct {
 		CaseID int `json:"case_id" jsonschema:"ID of the case to reopen"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_cases_reopen",
 		Description: "Reopen a previously closed case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesReopenArgs) (*mcp.CallToolResult, any, error) {
@@ -138,12 +148,12 @@ func registerCases(s *mcp.Server, c *client.Client) {
 		CaseID      int    `json:"case_id" jsonschema:"Case ID"`
 		CaseSummary string `json:"case_summary" jsonschema:"New case summary text (supports markdown)"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_cases_summary_update",
 		Description: "Update the summary/description of a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesSummaryUpdateArgs) (*mcp.CallToolResult, any, error) {
 		body := map[string]interface{}{"case_summary": args.CaseSummary}
-		data, err := c.Post(ctx, "/case/summary/update", cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, "/case/summary/update", client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -154,14 +164,29 @@ func registerCases(s *mcp.Server, c *client.Client) {
 	type casesExportArgs struct {
 		CaseID int `json:"case_id" jsonschema:"Case ID to export"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_cases_export",
 		Description: "Export a case as JSON",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args casesExportArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Get(ctx, "/case/export", cidQuery(args.CaseID))
+		data, err := c.Get(ctx, "/case/export", client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
 		return textResult(data), nil, nil
 	})
 }
+
+// describeCase summarises a case and everything deleting it would destroy.
+func describeCase(ctx context.Context, c *client.Client, caseID int) string {
+	info := describeObject(ctx, c, fmt.Sprintf("/manage/cases/%d", caseID), nil,
+		"case_name", "case_soc_id", "client_name", "open_date", "close_date")
+	return fmt.Sprintf("Case %d will be permanently deleted with all of its contents.\n%s\n"+
+		"IOCs: %s\nAssets: %s\nNotes: %s\nTimeline events: %s\nTasks: %s\nEvidences: %s",
+		caseID, info,
+		countItems(ctx, c, "/case/ioc/list", caseID, "ioc"),
+		countItems(ctx, c, "/case/assets/list", caseID, "assets"),
+		countNotes(ctx, c, caseID),
+		countItems(ctx, c, "/case/timeline/events/list", caseID, "timeline"),
+		countItems(ctx, c, "/case/tasks/list", caseID, "tasks"),
+		countItems(ctx, c, "/case/evidences/list", caseID, "evidences"))
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmTokenTTL is how long a confirm_token stays valid after it is issued.
+const confirmTokenTTL = 10 * time.Minute
+
+// confirmations tracks confirm_tokens issued to clients that cannot show an
+// elicitation form. A token is bound to the session, tool and target it was
+// issued for and can be redeemed once. Tokens are written to the server log
+// only, never returned to the client, so that the model cannot confirm on
+// the user's behalf.
+type confirmations struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time // key(session, tool, target, token) -> expiry
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{tokens: make(map[string]time.Time)}
+}
+
+// confirm asks the human to approve an irreversible operation on target and
+// returns nil once it may proceed. Otherwise it returns the result the tool
+// should return instead. describe is only called when the human has to be
+// shown what will be destroyed.
+//
+// Clients that support elicitation get a confirmation form. For other clients
+// a one-time confirm_token is written to the server log, where the operator
+// reads it; the user has to pass it back on a second call.
+func (ts *toolset) confirm(ctx context.Context, req *mcp.CallToolRequest, target string, token *string, describe func() string) *mcp.CallToolResult {
+	if len(ts.opts.Instances) > 0 {
+		target += " on instance " + instanceName(ctx)
+	}
+	if supportsElicitation(req.Session) {
+		res, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message: describe() + "\n\nThis cannot be undone. Proceed?",
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"confirm": map[string]any{
+						"type":        "boolean",
+						"title":       "Delete permanently",
+						"description": "Check to confirm " + target,
+					},
+				},
+				"required": []string{"confirm"},
+			},
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("asking for confirmation: %w", err))
+		}
+		if ok, _ := res.Content["confirm"].(bool); res.Action != "accept" || !ok {
+			return errorResult(fmt.Errorf("%s was not confirmed by the user; nothing was deleted", target))
+		}
+		return nil
+	}
+
+	key := req.Session.ID() + "\x00" + req.Params.Name + "\x00" + target + "\x00"
+	if token != nil && ts.confirmations.redeem(key+*token) {
+		return nil
+	}
+	if token != nil {
+		ts.logger().Warn("invalid confirm_token", "tool", req.Params.Name, "target", target)
+	}
+	issued := ts.confirmations.issue(key)
+	ts.logger().Warn("confirmation required", "tool", req.Params.Name, "target", target,
+		"confirm_token", issued, "valid_for", confirmTokenTTL.String())
+	return errorResult(fmt.Errorf("confirmation required, nothing was deleted.\n%s\n\n"+
+		"This cannot be undone. Show the above to the user. A confirm_token for it was written "+
+		"to the log of the dfir-iris-mcp server (valid for %s). Only if the user reads it there "+
+		"and gives it to you, call %s again with the same arguments and that confirm_token. "+
+		"Do not guess or reuse a token.",
+		describe(), confirmTokenTTL, req.Params.Name))
+}
+
+func supportsElicitation(ss *mcp.ServerSession) bool {
+	p := ss.InitializeParams()
+	return p != nil && p.Capabilities != nil && p.Capabilities.Elicitation != nil
+}
+
+func (cs *confirmations) issue(key string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	now := time.Now()
+	for k, exp := range cs.tokens {
+		if now.After(exp) {
+			delete(cs.tokens, k)
+		}
+	}
+	cs.tokens[key+token] = now.Add(confirmTokenTTL)
+	return token
+}
+
+func (cs *confirmations) redeem(key string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	exp, ok := cs.tokens[key]
+	delete(cs.tokens, key)
+	return ok && time.Now().Before(exp)
+}
+
+// describeObject fetches path and renders the given fields of the result as
+// "label: value" lines, for use in confirmation prompts.
+func describeObject(ctx context.Context, c *client.Client, path string, query map[string]string, fields ...string) string {
+	data, err := c.Get(ctx, path, query)
+	if err != nil {
+		return fmt.Sprintf("(details unavailable: %v)", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "(details unavailable)"
+	}
+	var lines []string
+	for _, f := range fields {
+		if v, ok := obj[f]; ok && v != nil {
+			lines = append(lines, fmt.Sprintf("%s: %v", f, v))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// countItems fetches a case-scoped list endpoint and returns the number of
+// entries under key, or under the top level if the result is an array.
+func countItems(ctx context.Context, c *client.Client, path string, caseID int, key string) string {
+	data, err := c.Get(ctx, path, client.CaseQuery(caseID))
+	if err != nil {
+		return "unknown"
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err == nil {
+		return fmt.Sprint(len(list))
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "unknown"
+	}
+	if err := json.Unmarshal(obj[key], &list); err != nil {
+		return "unknown"
+	}
+	return fmt.Sprint(len(list))
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent log writes.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// lastToken returns the confirm_token of the last log record with one.
+func (b *lockedBuffer) lastToken(t *testing.T) string {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	token := ""
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var rec struct {
+			Token string `json:"confirm_token"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("log record %q: %v", line, err)
+		}
+		if rec.Token != "" {
+			token = rec.Token
+		}
+	}
+	if token == "" {
+		t.Fatal("no confirm_token in the server log")
+	}
+	return token
+}
+
+func TestConfirmTokenOutOfBand(t *testing.T) {
+	var mu sync.Mutex
+	deleted := 0
+	iris := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/manage/cases/delete/5" {
+			mu.Lock()
+			deleted++
+			mu.Unlock()
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"case_id":5,"name":"Phishing"}}`))
+	}))
+	defer iris.Close()
+
+	var log lockedBuffer
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	err := RegisterAll(s, client.New(iris.URL, "key"), Options{
+		Include: []string{"dfir_iris_cases_delete"},
+		Logger:  slog.New(slog.NewJSONHandler(&log, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	call := func(token string) *mcp.CallToolResult {
+		t.Helper()
+		args := map[string]any{"case_id": 5}
+		if token != "" {
+			args["confirm_token"] = token
+		}
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "dfir_iris_cases_delete", Arguments: args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	wantDeleted := func(n int) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		if deleted != n {
+			t.Fatalf("IRIS deleted the case %d times, want %d", deleted, n)
+		}
+	}
+
+	res := call("")
+	if !res.IsError {
+		t.Fatal("first call succeeded, want a confirmation request")
+	}
+	wantDeleted(0)
+	token := log.lastToken(t)
+	out, _ := json.Marshal(res)
+	if strings.Contains(string(out), token) {
+		t.Fatalf("confirm_token %s was returned to the client: %s", token, out)
+	}
+
+	if res := call("0123456789abcdef"); !res.IsError {
+		t.Fatal("call with a made-up token succeeded")
+	}
+	wantDeleted(0)
+
+	token = log.lastToken(t)
+	if res := call(token); res.IsError {
+		t.Fatalf("call with the logged token failed: %+v", res.Content)
+	}
+	wantDeleted(1)
+
+	if res := call(token); !res.IsError {
+		t.Fatal("a redeemed token was accepted again")
+	}
+	wantDeleted(1)
+}
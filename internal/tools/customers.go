@@ -9,9 +9,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerCustomers(s *mcp.Server, c *client.Client) {
+func registerCustomers(ts *toolset, c *client.Client) {
 	// List customers
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_customers_list",
 		Description: "List all customers in DFIR-IRIS",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -28,7 +28,7 @@ func registerCustomers(s *mcp.Server, c *client.Client) {
 		CustomerDescription *string `json:"customer_description,omitempty" jsonschema:"Customer description"`
 		CustomerSLA         *string `json:"customer_sla,omitempty" jsonschema:"SLA terms"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_customers_add",
 		Description: "Create a new customer",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args customersAddArgs) (*mcp.CallToolResult, any, error) {
@@ -46,7 +46,7 @@ func registerCustomers(s *mcp.Server, c *client.Client) {
 		CustomerDescription *string `json:"customer_description,omitempty" jsonschema:"New description"`
 		CustomerSLA         *string `json:"customer_sla,omitempty" jsonschema:"New SLA terms"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_customers_update",
 		Description: "Update a customer",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args customersUpdateArgs) (*mcp.CallToolResult, any, error) {
@@ -60,12 +60,20 @@ func registerCustomers(s *mcp.Server, c *client.Client) {
 
 	// Delete customer
 	type customersDeleteArgs struct {
-		CustomerID int `json:"customer_id" jsonschema:"Customer ID to delete"`
+		CustomerID   int     `json:"customer_id" jsonschema:"Customer ID to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_customers_delete",
-		Description: "Delete a customer (irreversible)",
+		Description: "Delete a customer (irreversible, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args customersDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of customer %d", args.CustomerID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return fmt.Sprintf("Customer %d will be permanently deleted.\n%s", args.CustomerID,
+				describeObject(ctx, c, fmt.Sprintf("/manage/customers/%d", args.CustomerID), nil, "customer_name", "customer_description"))
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/manage/customers/delete/%d", args.CustomerID)
 		data, err := c.Post(ctx, path, nil, nil)
 		if err != nil {
@@ -2,27 +2,30 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerDatastore(s *mcp.Server, c *client.Client) {
+func registerDatastore(ts *toolset, c *client.Client) {
 	// List datastore tree
 	type datastoreTreeArgs struct {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_datastore_tree",
 		Description: "Get the datastore folder/file tree for a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreTreeArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Get(ctx, "/datastore/list/tree", cidQuery(args.CaseID))
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreTreeArgs) (*mcp.CallToolResult, *model.DatastoreTree, error) {
+		data, err := c.Get(ctx, "/datastore/list/tree", client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.DatastoreTree](data)
 	})
 
 	// Get file info
@@ -30,16 +33,16 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 		FileID int `json:"file_id" jsonschema:"Datastore file ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_datastore_file_get",
 		Description: "Get metadata of a file in the datastore",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileGetArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileGetArgs) (*mcp.CallToolResult, *model.DatastoreNode, error) {
 		path := fmt.Sprintf("/datastore/file/info/%d", args.FileID)
-		data, err := c.Get(ctx, path, cidQuery(args.CaseID))
+		data, err := c.Get(ctx, path, client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.DatastoreNode](data)
 	})
 
 	// Add file (metadata only — binary upload not supported via MCP)
@@ -52,16 +55,16 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		FileIsIoc        *bool   `json:"file_is_ioc,omitempty" jsonschema:"Whether file is an IOC"`
 		FileIsEvidence   *bool   `json:"file_is_evidence,omitempty" jsonschema:"Whether file is evidence"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_datastore_file_add",
 		Description: "Add a file entry to the datastore (metadata only, binary upload not supported via MCP)",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileAddArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileAddArgs) (*mcp.CallToolResult, *model.DatastoreNode, error) {
 		path := fmt.Sprintf("/datastore/file/add/%d", args.ParentID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), toBody(args, "case_id", "parent_id"))
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), toBody(args, "case_id", "parent_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.DatastoreNode](data)
 	})
 
 	// Update file
@@ -71,16 +74,16 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		FileOriginalName *string `json:"file_original_name,omitempty" jsonschema:"New filename"`
 		FileDescription  *string `json:"file_description,omitempty" jsonschema:"New description"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_datastore_file_update",
 		Description: "Update a file's metadata in the datastore",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileUpdateArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileUpdateArgs) (*mcp.CallToolResult, *model.DatastoreNode, error) {
 		path := fmt.Sprintf("/datastore/file/update/%d", args.FileID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), toBody(args, "case_id", "file_id"))
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), toBody(args, "case_id", "file_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.DatastoreNode](data)
 	})
 
 	// Delete file
@@ -88,12 +91,12 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 		FileID int `json:"file_id" jsonschema:"File ID to delete"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_datastore_file_delete",
 		Description: "Delete a file from the datastore",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileDeleteArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/datastore/file/delete/%d", args.FileID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), nil)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -106,13 +109,13 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		FileID              int `json:"file_id" jsonschema:"File ID to move"`
 		DestinationFolderID int `json:"destination_folder_id" jsonschema:"Destination folder ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_datastore_file_move",
 		Description: "Move a file to a different folder in the datastore",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFileMoveArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/datastore/file/move/%d", args.FileID)
 		body := map[string]interface{}{"destination_folder_id": args.DestinationFolderID}
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -125,7 +128,7 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		FolderName string `json:"folder_name" jsonschema:"Name of the new folder"`
 		ParentID   int    `json:"parent_id" jsonschema:"Parent folder ID (0 for root)"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_datastore_folder_add",
 		Description: "Create a new folder in the datastore",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFolderAddArgs) (*mcp.CallToolResult, any, error) {
@@ -133,7 +136,7 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 			"folder_name": args.FolderName,
 			"parent_id":   args.ParentID,
 		}
-		data, err := c.Post(ctx, "/datastore/folder/add", cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, "/datastore/folder/add", client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -142,15 +145,22 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 
 	// Delete folder
 	type datastoreFolderDeleteArgs struct {
-		CaseID   int `json:"case_id" jsonschema:"Case ID"`
-		FolderID int `json:"folder_id" jsonschema:"Folder ID to delete"`
+		CaseID       int     `json:"case_id" jsonschema:"Case ID"`
+		FolderID     int     `json:"folder_id" jsonschema:"Folder ID to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_datastore_folder_delete",
-		Description: "Delete a folder from the datastore (and all contents)",
+		Description: "Delete a folder from the datastore (and all contents, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFolderDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of datastore folder %d in case %d", args.FolderID, args.CaseID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return describeDatastoreFolder(ctx, c, args.CaseID, args.FolderID)
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/datastore/folder/delete/%d", args.FolderID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), nil)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -163,13 +173,13 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		FolderID   int    `json:"folder_id" jsonschema:"Folder ID to rename"`
 		FolderName string `json:"folder_name" jsonschema:"New folder name"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_datastore_folder_rename",
 		Description: "Rename a folder in the datastore",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFolderRenameArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/datastore/folder/rename/%d", args.FolderID)
 		body := map[string]interface{}{"folder_name": args.FolderName}
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -182,16 +192,69 @@ func registerDatastore(s *mcp.Server, c *client.Client) {
 		FolderID            int `json:"folder_id" jsonschema:"Folder ID to move"`
 		DestinationFolderID int `json:"destination_folder_id" jsonschema:"Destination parent folder ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_datastore_folder_move",
 		Description: "Move a folder to a different parent folder in the datastore",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args datastoreFolderMoveArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/datastore/folder/move/%d", args.FolderID)
 		body := map[string]interface{}{"destination_folder_id": args.DestinationFolderID}
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
 		return textResult(data), nil, nil
 	})
 }
+
+// datastoreNode is a folder ("d-<id>") or file ("f-<id>") in the datastore
+// tree. Folders hold their entries in Children, keyed the same way.
+type datastoreNode struct {
+	Name     string                   `json:"name"`
+	Children map[string]datastoreNode `json:"children"`
+}
+
+// count returns the number of folders and files below n.
+func (n datastoreNode) count() (folders, files int) {
+	for key, child := range n.Children {
+		if strings.HasPrefix(key, "d-") {
+			folders++
+		} else {
+			files++
+		}
+		d, f := child.count()
+		folders += d
+		files += f
+	}
+	return folders, files
+}
+
+func describeDatastoreFolder(ctx context.Context, c *client.Client, caseID, folderID int) string {
+	data, err := c.Get(ctx, "/datastore/list/tree", client.CaseQuery(caseID))
+	var tree map[string]datastoreNode
+	if err == nil {
+		err = json.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		return fmt.Sprintf("Datastore folder %d of case %d will be permanently deleted with all its contents (details unavailable: %v).", folderID, caseID, err)
+	}
+	key := fmt.Sprintf("d-%d", folderID)
+	var find func(map[string]datastoreNode) (datastoreNode, bool)
+	find = func(nodes map[string]datastoreNode) (datastoreNode, bool) {
+		if n, ok := nodes[key]; ok {
+			return n, true
+		}
+		for _, n := range nodes {
+			if found, ok := find(n.Children); ok {
+				return found, true
+			}
+		}
+		return datastoreNode{}, false
+	}
+	n, ok := find(tree)
+	if !ok {
+		return fmt.Sprintf("Datastore folder %d was not found in case %d.", folderID, caseID)
+	}
+	folders, files := n.count()
+	return fmt.Sprintf("Datastore folder %q (%d) of case %d will be permanently deleted with its %d subfolders and %d files.",
+		n.Name, folderID, caseID, folders, files)
+}
@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errorMetaKey is the _meta key of error results holding their toolError.
+const errorMetaKey = "dfir-iris-mcp/error"
+
+// toolError is the error of an error result, so that the model can tell a
+// typo from an outage. It is sent in _meta under errorMetaKey and, by tools
+// without an output schema, as structured content under "error".
+type toolError struct {
+	Kind      client.ErrorKind    `json:"kind"`
+	Status    int                 `json:"status,omitempty"` // HTTP status from IRIS
+	Message   string              `json:"message"`
+	Fields    map[string][]string `json:"fields,omitempty"`
+	Retryable bool                `json:"retryable"`
+	Hint      string              `json:"hint,omitempty"`
+}
+
+// kindHints tell the model how to recover from each kind of error.
+var kindHints = map[client.ErrorKind]string{
+	client.KindAuth:       "The IRIS API key is missing or was rejected. Retrying will not help; the user must fix the key.",
+	client.KindPermission: "The user of the API key may not access this case or action. Retrying will not help; work on another case or ask an IRIS administrator for access.",
+	client.KindNotFound:   "The object does not exist. List the available objects and retry with a valid ID.",
+	client.KindValidation: "IRIS rejected the arguments. Correct them according to the message before retrying.",
+	client.KindConflict:   "The object already exists or changed meanwhile. Fetch its current state before retrying.",
+	client.KindRateLimit:  "IRIS is throttling requests. Wait before retrying, and avoid calling tools over many objects at once.",
+	client.KindServer:     "IRIS failed internally. Retry once later; if it fails again, report the error instead of retrying.",
+	client.KindNetwork:    "IRIS could not be reached. Retry once later; if it fails again, report the error.",
+	client.KindTimeout:    "IRIS did not answer in time. Retry once, or request less data with filters, per_page or fields.",
+	client.KindReadOnly:   "This server is read-only. Do not retry; the change must be made in IRIS directly.",
+}
+
+// idTools names the tool listing the valid values of an ID argument.
+var idTools = map[string]string{
+	"case_id":            "dfir_iris_cases_list",
+	"alert_id":           "dfir_iris_alerts_filter",
+	"customer_id":        "dfir_iris_customers_list",
+	"user_id":            "dfir_iris_users_list",
+	"group_id":           "dfir_iris_groups_list",
+	"asset_id":           "dfir_iris_assets_list",
+	"ioc_id":             "dfir_iris_iocs_list",
+	"event_id":           "dfir_iris_timeline_list",
+	"task_id":            "dfir_iris_tasks_list",
+	"evidence_id":        "dfir_iris_evidences_list",
+	"note_id":            "dfir_iris_notes_groups_list",
+	"ioc_type_id":        "dfir_iris_settings_ioc_types",
+	"asset_type_id":      "dfir_iris_settings_asset_types",
+	"event_category_id":  "dfir_iris_settings_event_categories",
+	"case_template_id":   "dfir_iris_settings_case_templates",
+	"classification_id":  "dfir_iris_settings_classifications",
+	"evidence_type_id":   "dfir_iris_settings_evidence_types",
+	"task_status_id":     "dfir_iris_settings_task_status",
+	"analysis_status_id": "dfir_iris_settings_analysis_status",
+	"state_id":           "dfir_iris_settings_case_states",
+}
+
+// errorResult returns a tool error result for err, with its kind and a
+// generic hint. explainErrors refines the hint from the call arguments.
+func errorResult(err error) *mcp.CallToolResult {
+	te := &toolError{
+		Kind:    client.Kind(err),
+		Message: err.Error(),
+		Fields:  client.Fields(err),
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		te.Status = apiErr.StatusCode
+	}
+	te.Retryable = te.Kind.Retryable()
+	te.Hint = kindHints[te.Kind]
+	return te.result()
+}
+
+func (te *toolError) result() *mcp.CallToolResult {
+	text := te.Message
+	if te.Hint != "" {
+		text += "\n\nHint: " + te.Hint
+	}
+	return &mcp.CallToolResult{
+		Meta:              mcp.Meta{errorMetaKey: te},
+		Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+		StructuredContent: map[string]any{"error": te},
+		IsError:           true,
+	}
+}
+
+// toolErrorOf returns the toolError of res, if it is an error result made by
+// errorResult.
+func toolErrorOf(res *mcp.CallToolResult) (*toolError, bool) {
+	if res == nil || !res.IsError {
+		return nil, false
+	}
+	te, ok := res.Meta[errorMetaKey].(*toolError)
+	return te, ok
+}
+
+// errorsOutsideSchema wraps h, a tool with an output schema, so that its
+// error results carry no structured content: clients validate structured
+// content against the schema, which an error does not match. The error
+// stays in the text and _meta of the result.
+func errorsOutsideSchema[In any](h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		res, out, err := h(ctx, req, in)
+		if res != nil && res.IsError {
+			res.StructuredContent = nil
+		}
+		return res, out, err
+	}
+}
+
+// explainErrors wraps h so that the hints of its errors name the arguments
+// at fault and the tools listing their valid values.
+func explainErrors[In any](h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		res, out, err := h(ctx, req, in)
+		if err != nil || res == nil || !res.IsError {
+			return res, out, err
+		}
+		te, ok := toolErrorOf(res)
+		if !ok {
+			return res, out, err
+		}
+		var args map[string]any
+		_ = json.Unmarshal(req.Params.Arguments, &args)
+		if hint := argHint(te, args); hint != "" {
+			te.Hint = hint
+			res = te.result()
+		}
+		return res, out, nil
+	}
+}
+
+// argHint returns a hint specific to the arguments of the failed call, or
+// an empty string if there is none.
+func argHint(te *toolError, args map[string]any) string {
+	switch te.Kind {
+	case client.KindNotFound:
+		var suspects []string
+		for _, name := range slices.Sorted(maps.Keys(args)) {
+			if tool, ok := idTools[name]; ok && args[name] != nil {
+				suspects = append(suspects, fmt.Sprintf("%s %v (check with %s)", name, args[name], tool))
+			}
+		}
+		if len(suspects) > 0 {
+			return "One of these may not exist: " + strings.Join(suspects, ", ") + ". Retry with a valid ID."
+		}
+	case client.KindValidation:
+		var parts []string
+		for _, name := range slices.Sorted(maps.Keys(te.Fields)) {
+			part := name
+			if v, ok := args[name]; ok {
+				part += fmt.Sprintf(" %v", v)
+			}
+			if msgs := te.Fields[name]; len(msgs) != 1 || msgs[0] != te.Message {
+				part += ": " + strings.Join(msgs, "; ")
+			}
+			if tool, ok := idTools[name]; ok {
+				part += fmt.Sprintf(" (call %s for the valid values)", tool)
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) > 0 {
+			return "Fix these arguments before retrying: " + strings.Join(parts, ". ") + "."
+		}
+	}
+	return ""
+}
@@ -5,24 +5,25 @@ import (
 	"fmt"
 
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerEvidences(s *mcp.Server, c *client.Client) {
+func registerEvidences(ts *toolset, c *client.Client) {
 	// List evidences
 	type evidencesListArgs struct {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_evidences_list",
 		Description: "List all evidences in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesListArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Get(ctx, "/case/evidences/list", cidQuery(args.CaseID))
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesListArgs) (*mcp.CallToolResult, *model.EvidenceList, error) {
+		data, err := c.Get(ctx, "/case/evidences/list", client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.EvidenceList](data)
 	})
 
 	// Get evidence
@@ -30,16 +31,16 @@ func registerEvidences(s *mcp.Server, c *client.Client) {
 		CaseID     int `json:"case_id" jsonschema:"Case ID"`
 		EvidenceID int `json:"evidence_id" jsonschema:"Evidence ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_evidences_get",
 		Description: "Get details of a specific evidence item",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesGetArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesGetArgs) (*mcp.CallToolResult, *model.Evidence, error) {
 		path := fmt.Sprintf("/case/evidences/%d", args.EvidenceID)
-		data, err := c.Get(ctx, path, cidQuery(args.CaseID))
+		data, err := c.Get(ctx, path, client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Evidence](data)
 	})
 
 	// Add evidence
@@ -51,15 +52,15 @@ func registerEvidences(s *mcp.Server, c *client.Client) {
 		FileDescription *string `json:"file_description,omitempty" jsonschema:"Description of the evidence"`
 		EvidenceTypeID  *int    `json:"type_id,omitempty" jsonschema:"Evidence type ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_evidences_add",
 		Description: "Add a new evidence record to a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesAddArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Post(ctx, "/case/evidences/add", cidQuery(args.CaseID), toBody(args, "case_id"))
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesAddArgs) (*mcp.CallToolResult, *model.Evidence, error) {
+		data, err := c.Post(ctx, "/case/evidences/add", client.CaseQuery(args.CaseID), toBody(args, "case_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Evidence](data)
 	})
 
 	// Update evidence
@@ -72,16 +73,16 @@ func registerEvidences(s *mcp.Server, c *client.Client) {
 		FileDescription *string `json:"file_description,omitempty" jsonschema:"New description"`
 		EvidenceTypeID  *int    `json:"type_id,omitempty" jsonschema:"New evidence type ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_evidences_update",
 		Description: "Update an evidence record in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesUpdateArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesUpdateArgs) (*mcp.CallToolResult, *model.Evidence, error) {
 		path := fmt.Sprintf("/case/evidences/update/%d", args.EvidenceID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), toBody(args, "case_id", "evidence_id"))
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), toBody(args, "case_id", "evidence_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Evidence](data)
 	})
 
 	// Delete evidence
@@ -89,12 +90,12 @@ func registerEvidences(s *mcp.Server, c *client.Client) {
 		CaseID     int `json:"case_id" jsonschema:"Case ID"`
 		EvidenceID int `json:"evidence_id" jsonschema:"Evidence ID to delete"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_evidences_delete",
 		Description: "Delete an evidence record from a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args evidencesDeleteArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/case/evidences/delete/%d", args.EvidenceID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), nil)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -9,9 +9,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerGroups(s *mcp.Server, c *client.Client) {
+func registerGroups(ts *toolset, c *client.Client) {
 	// List groups
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_groups_list",
 		Description: "List all groups in DFIR-IRIS",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -28,7 +28,7 @@ func registerGroups(s *mcp.Server, c *client.Client) {
 		GroupDescription *string `json:"group_description,omitempty" jsonschema:"Group description"`
 		GroupPermissions *int    `json:"group_permissions,omitempty" jsonschema:"Permission bitmask"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_groups_add",
 		Description: "Create a new group (admin operation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args groupsAddArgs) (*mcp.CallToolResult, any, error) {
@@ -46,7 +46,7 @@ func registerGroups(s *mcp.Server, c *client.Client) {
 		GroupDescription *string `json:"group_description,omitempty" jsonschema:"New description"`
 		GroupPermissions *int    `json:"group_permissions,omitempty" jsonschema:"New permission bitmask"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_groups_update",
 		Description: "Update a group (admin operation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args groupsUpdateArgs) (*mcp.CallToolResult, any, error) {
@@ -60,12 +60,20 @@ func registerGroups(s *mcp.Server, c *client.Client) {
 
 	// Delete group
 	type groupsDeleteArgs struct {
-		GroupID int `json:"group_id" jsonschema:"Group ID to delete"`
+		GroupID      int     `json:"group_id" jsonschema:"Group ID to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_groups_delete",
-		Description: "Delete a group (admin operation, irreversible)",
+		Description: "Delete a group (admin operation, irreversible, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args groupsDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of group %d", args.GroupID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return fmt.Sprintf("Group %d will be permanently deleted.\n%s", args.GroupID,
+				describeObject(ctx, c, fmt.Sprintf("/manage/groups/%d", args.GroupID), nil, "group_name", "group_description"))
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/manage/groups/delete/%d", args.GroupID)
 		data, err := c.Post(ctx, path, nil, nil)
 		if err != nil {
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Instance is an additional IRIS deployment that tools can be pointed at
+// through their instance argument.
+type Instance struct {
+	Name   string
+	Client *client.Client
+}
+
+const instancesListTool = "dfir_iris_instances_list"
+
+type instanceContextKey struct{}
+
+// instanceName returns the instance a tool call was routed to.
+func instanceName(ctx context.Context) string {
+	name, _ := ctx.Value(instanceContextKey{}).(string)
+	return name
+}
+
+// instanceNames returns the primary instance followed by the others in
+// alphabetical order.
+func (ts *toolset) instanceNames() []string {
+	names := make([]string, 0, len(ts.opts.Instances)+1)
+	for _, inst := range ts.opts.Instances {
+		names = append(names, inst.Name)
+	}
+	sort.Strings(names)
+	return append([]string{ts.opts.Primary}, names...)
+}
+
+// instanceArg is the schema of the instance argument.
+func (ts *toolset) instanceArg() *jsonschema.Schema {
+	var enum []any
+	for _, name := range ts.instanceNames() {
+		enum = append(enum, name)
+	}
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        enum,
+		Description: fmt.Sprintf("IRIS instance to call (default %q, see %s)", ts.opts.Primary, instancesListTool),
+	}
+}
+
+// routeInstance wraps h so that its requests go to the instance named by the
+// call's instance argument.
+func routeInstance[In any](ts *toolset, h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	clients := make(map[string]*client.Client, len(ts.opts.Instances))
+	for _, inst := range ts.opts.Instances {
+		clients[inst.Name] = inst.Client
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		var args struct {
+			Instance string `json:"instance"`
+		}
+		_ = json.Unmarshal(req.Params.Arguments, &args)
+		name := args.Instance
+		if name == "" {
+			name = ts.opts.Primary
+		}
+		if c, ok := clients[name]; ok {
+			ctx = client.WithInstance(ctx, c)
+		} else if name != ts.opts.Primary {
+			return errorResult(fmt.Errorf("unknown instance %q (available: %s)",
+				name, strings.Join(ts.instanceNames(), ", "))), nil, nil
+		}
+		return h(context.WithValue(ctx, instanceContextKey{}, name), req, in)
+	}
+}
+
+func registerInstances(ts *toolset, c *client.Client) {
+	addTool(ts, toolRead, &mcp.Tool{
+		Name:        instancesListTool,
+		Description: "List the IRIS instances this server can reach. Pass a name as the instance argument of any other tool to call that instance",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		type entry struct {
+			Name     string `json:"name"`
+			URL      string `json:"url"`
+			Primary  bool   `json:"primary"`
+			ReadOnly bool   `json:"read_only"`
+		}
+		list := []entry{{Name: ts.opts.Primary, URL: c.BaseURL(), Primary: true, ReadOnly: c.ReadOnly()}}
+		for _, inst := range ts.opts.Instances {
+			list = append(list, entry{Name: inst.Name, URL: inst.Client.BaseURL(), ReadOnly: inst.Client.ReadOnly()})
+		}
+		sort.SliceStable(list[1:], func(i, j int) bool { return list[1+i].Name < list[1+j].Name })
+		data, err := json.Marshal(list)
+		if err != nil {
+			return errorResult(err), nil, nil
+		}
+		return textResult(data), nil, nil
+	})
+}
@@ -2,27 +2,29 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerNotes(s *mcp.Server, c *client.Client) {
+func registerNotes(ts *toolset, c *client.Client) {
 	// List note directories (note groups)
 	type notesDirsListArgs struct {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_notes_groups_list",
 		Description: "List all note directories (groups) in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesDirsListArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Get(ctx, "/case/notes/directories/filter", cidQuery(args.CaseID))
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesDirsListArgs) (*mcp.CallToolResult, *model.NoteDirectoryList, error) {
+		data, err := c.Get(ctx, "/case/notes/directories/filter", client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.NoteDirectoryList](data)
 	})
 
 	// Add note directory
@@ -30,12 +32,12 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		CaseID int    `json:"case_id" jsonschema:"Case ID"`
 		Name   string `json:"name" jsonschema:"Name of the note directory"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_notes_groups_add",
 		Description: "Create a new note directory (group) in a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesDirsAddArgs) (*mcp.CallToolResult, any, error) {
 		body := map[string]interface{}{"name": args.Name}
-		data, err := c.Post(ctx, "/case/notes/directories/add", cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, "/case/notes/directories/add", client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -48,13 +50,13 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		DirectoryID int    `json:"directory_id" jsonschema:"Note directory ID to update"`
 		Name        string `json:"name" jsonschema:"New directory name"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_notes_groups_update",
 		Description: "Update a note directory (group) in a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesDirsUpdateArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/case/notes/directories/update/%d", args.DirectoryID)
 		body := map[string]interface{}{"name": args.Name}
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -63,15 +65,22 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 
 	// Delete note directory
 	type notesDirsDeleteArgs struct {
-		CaseID      int `json:"case_id" jsonschema:"Case ID"`
-		DirectoryID int `json:"directory_id" jsonschema:"Note directory ID to delete"`
+		CaseID       int     `json:"case_id" jsonschema:"Case ID"`
+		DirectoryID  int     `json:"directory_id" jsonschema:"Note directory ID to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_notes_groups_delete",
-		Description: "Delete a note directory from a case (deletes all notes in it)",
+		Description: "Delete a note directory from a case (deletes all notes in it, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesDirsDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of note directory %d in case %d", args.DirectoryID, args.CaseID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return describeNoteDirectory(ctx, c, args.CaseID, args.DirectoryID)
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/case/notes/directories/delete/%d", args.DirectoryID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), nil)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -83,16 +92,16 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 		NoteID int `json:"note_id" jsonschema:"Note ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_notes_get",
 		Description: "Get details of a specific note",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesGetArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesGetArgs) (*mcp.CallToolResult, *model.Note, error) {
 		path := fmt.Sprintf("/case/notes/%d", args.NoteID)
-		data, err := c.Get(ctx, path, cidQuery(args.CaseID))
+		data, err := c.Get(ctx, path, client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Note](data)
 	})
 
 	// Add note
@@ -102,20 +111,20 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		NoteContent string `json:"note_content" jsonschema:"Content of the note (supports markdown)"`
 		DirectoryID int    `json:"directory_id" jsonschema:"Note directory ID to add the note to"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_notes_add",
 		Description: "Add a new note to a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesAddArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesAddArgs) (*mcp.CallToolResult, *model.Note, error) {
 		body := map[string]interface{}{
 			"note_title":   args.NoteTitle,
 			"note_content": args.NoteContent,
 			"directory_id": args.DirectoryID,
 		}
-		data, err := c.Post(ctx, "/case/notes/add", cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, "/case/notes/add", client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Note](data)
 	})
 
 	// Update note
@@ -126,16 +135,16 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		NoteContent *string `json:"note_content,omitempty" jsonschema:"New note content"`
 		DirectoryID *int    `json:"directory_id,omitempty" jsonschema:"Move note to a different directory"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_notes_update",
 		Description: "Update an existing note in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesUpdateArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesUpdateArgs) (*mcp.CallToolResult, *model.Note, error) {
 		path := fmt.Sprintf("/case/notes/update/%d", args.NoteID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), toBody(args, "case_id", "note_id"))
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), toBody(args, "case_id", "note_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Note](data)
 	})
 
 	// Delete note
@@ -143,12 +152,12 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 		NoteID int `json:"note_id" jsonschema:"Note ID to delete"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_notes_delete",
 		Description: "Delete a note from a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesDeleteArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/case/notes/delete/%d", args.NoteID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), nil)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -160,15 +169,68 @@ func registerNotes(s *mcp.Server, c *client.Client) {
 		CaseID     int    `json:"case_id" jsonschema:"Case ID"`
 		SearchTerm string `json:"search_term" jsonschema:"Text to search for in notes"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_notes_search",
 		Description: "Search notes in a case by keyword",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesSearchArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args notesSearchArgs) (*mcp.CallToolResult, *model.NoteList, error) {
 		body := map[string]interface{}{"search_term": args.SearchTerm}
-		data, err := c.Post(ctx, "/case/notes/search", cidQuery(args.CaseID), body)
+		data, err := c.Post(ctx, "/case/notes/search", client.CaseQuery(args.CaseID), body)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.NoteList](data)
 	})
 }
+
+func noteDirectories(ctx context.Context, c *client.Client, caseID int) (*model.NoteDirectoryList, error) {
+	data, err := c.Get(ctx, "/case/notes/directories/filter", client.CaseQuery(caseID))
+	if err != nil {
+		return nil, err
+	}
+	var dirs model.NoteDirectoryList
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		return nil, fmt.Errorf("decoding note directories: %w", err)
+	}
+	return &dirs, nil
+}
+
+// countNotes returns the number of notes in a case, or "unknown".
+func countNotes(ctx context.Context, c *client.Client, caseID int) string {
+	dirs, err := noteDirectories(ctx, c, caseID)
+	if err != nil {
+		return "unknown"
+	}
+	n := 0
+	for _, d := range dirs.Directories {
+		n += len(d.Notes)
+	}
+	return fmt.Sprint(n)
+}
+
+func describeNoteDirectory(ctx context.Context, c *client.Client, caseID, dirID int) string {
+	dirs, err := noteDirectories(ctx, c, caseID)
+	if err != nil {
+		return fmt.Sprintf("Note directory %d of case %d will be permanently deleted with all its notes (details unavailable: %v).", dirID, caseID, err)
+	}
+	// Directories come after their parent, so one pass collects the
+	// whole subtree of dirID.
+	var dir *model.NoteDirectory
+	subtree := map[int]bool{dirID: true}
+	subdirs, notes := 0, 0
+	for i, d := range dirs.Directories {
+		switch {
+		case d.ID == dirID:
+			dir = &dirs.Directories[i]
+			notes += len(d.Notes)
+		case dir != nil && subtree[d.ParentID]:
+			subtree[d.ID] = true
+			subdirs++
+			notes += len(d.Notes)
+		}
+	}
+	if dir == nil {
+		return fmt.Sprintf("Note directory %d was not found in case %d.", dirID, caseID)
+	}
+	return fmt.Sprintf("Note directory %q (%d) of case %d will be permanently deleted with its %d subdirectories and %d notes.",
+		dir.Name, dir.ID, caseID, subdirs, notes)
+}
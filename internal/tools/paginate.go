@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"dfir-iris-mcp/internal/client"
+)
+
+// fetchAllPageSize is the page size used by fetch_all when the caller does
+// not choose one, to keep the number of requests down.
+const fetchAllPageSize = 100
+
+// pageCursor is the decoded form of a next_cursor. It carries the whole
+// query so that a caller can continue with the cursor alone, and Skip
+// items of Page already returned when fetch_all stopped mid-page.
+type pageCursor struct {
+	Query map[string]string `json:"q"`
+	Page  int               `json:"p"`
+	Skip  int               `json:"s,omitempty"`
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || c.Page < 1 || c.Skip < 0 {
+		return c, errors.New("invalid cursor: pass next_cursor from the previous response unchanged")
+	}
+	return c, nil
+}
+
+// filterPage is one page of an IRIS filter endpoint.
+type filterPage struct {
+	Total       int  `json:"total"`
+	CurrentPage int  `json:"current_page"`
+	LastPage    int  `json:"last_page"`
+	NextPage    *int `json:"next_page"`
+}
+
+// next returns the page following p, or 0 if p is the last one.
+func (p filterPage) next() int {
+	switch {
+	case p.NextPage != nil:
+		return *p.NextPage
+	case p.CurrentPage > 0 && p.CurrentPage < p.LastPage:
+		return p.CurrentPage + 1
+	}
+	return 0
+}
+
+// filterPages queries the IRIS filter endpoint at path, whose pages list
+// their items under key. It starts at cursor if set, or else at the page in
+// query. With fetchAll it follows pages until none are left or limit items
+// are collected.
+//
+// The result is the last IRIS page with key holding every collected item and
+// next_cursor set when more items remain.
+func filterPages(ctx context.Context, c *client.Client, path, key string, query map[string]string, cursor string, fetchAll bool, limit int) (json.RawMessage, error) {
+	cur := pageCursor{Query: query, Page: 1}
+	if cursor != "" {
+		var err error
+		if cur, err = decodePageCursor(cursor); err != nil {
+			return nil, err
+		}
+	} else {
+		if p, err := strconv.Atoi(query["page"]); err == nil && p > 0 {
+			cur.Page = p
+		}
+		if fetchAll && query["per_page"] == "" {
+			cur.Query = maps.Clone(query)
+			cur.Query["per_page"] = strconv.Itoa(fetchAllPageSize)
+		}
+	}
+	if cur.Query == nil {
+		cur.Query = make(map[string]string)
+	}
+
+	var (
+		items []json.RawMessage
+		last  map[string]json.RawMessage
+		next  *pageCursor
+	)
+	for {
+		q := maps.Clone(cur.Query)
+		q["page"] = strconv.Itoa(cur.Page)
+		data, err := c.Get(ctx, path, q)
+		if err != nil {
+			return nil, err
+		}
+		var page filterPage
+		last = nil
+		var pageItems []json.RawMessage
+		// An empty body or "data": null is a page without items.
+		if len(bytes.TrimSpace(data)) > 0 {
+			err = json.Unmarshal(data, &last)
+			if err == nil {
+				err = json.Unmarshal(data, &page)
+			}
+		}
+		if err == nil && last[key] != nil {
+			err = json.Unmarshal(last[key], &pageItems)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s page %d: %w", path, cur.Page, err)
+		}
+		if last == nil {
+			last = make(map[string]json.RawMessage)
+		}
+		pageItems = pageItems[min(cur.Skip, len(pageItems)):]
+
+		next = nil
+		if room := limit - len(items); fetchAll && limit > 0 && len(pageItems) > room {
+			items = append(items, pageItems[:room]...)
+			next = &pageCursor{Query: cur.Query, Page: cur.Page, Skip: cur.Skip + room}
+			break
+		}
+		items = append(items, pageItems...)
+		if n := page.next(); n > 0 {
+			next = &pageCursor{Query: cur.Query, Page: n}
+		}
+		if !fetchAll || next == nil || len(pageItems) == 0 || limit > 0 && len(items) >= limit {
+			break
+		}
+		cur = *next
+	}
+
+	list := []byte{'['}
+	for i, item := range items {
+		if i > 0 {
+			list = append(list, ',')
+		}
+		list = append(list, item...)
+	}
+	last[key] = append(list, ']')
+	delete(last, "next_cursor")
+	if next != nil {
+		last["next_cursor"], _ = json.Marshal(next.encode())
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(last); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
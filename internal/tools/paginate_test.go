@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"dfir-iris-mcp/internal/client"
+)
+
+// fakeFilter serves /manage/cases/filter with cases 1 to 5, per_page (2 by
+// default) at a time. The case_name "null" and "empty" answer with
+// "data": null and an empty body.
+func fakeFilter(t *testing.T) *client.Client {
+	t.Helper()
+	iris := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch q.Get("case_name") {
+		case "null":
+			_, _ = w.Write([]byte(`{"status":"success","data":null}`))
+			return
+		case "empty":
+			return
+		case "", "x":
+		default:
+			t.Errorf("filter lost: case_name %q", q.Get("case_name"))
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		perPage, _ := strconv.Atoi(q.Get("per_page"))
+		if perPage == 0 {
+			perPage = 2
+		}
+		const total = 5
+		lastPage := (total + perPage - 1) / perPage
+		var ids []string
+		for id := (page-1)*perPage + 1; id <= min(page*perPage, total); id++ {
+			ids = append(ids, fmt.Sprintf(`{"case_id":%d}`, id))
+		}
+		next := "null"
+		if page < lastPage {
+			next = strconv.Itoa(page + 1)
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"total":%d,"current_page":%d,"last_page":%d,"next_page":%s,"cases":[%s]}}`,
+			total, page, lastPage, next, strings.Join(ids, ","))
+	}))
+	t.Cleanup(iris.Close)
+	return client.New(iris.URL, "key")
+}
+
+type casesPage struct {
+	Cases []struct {
+		ID int `json:"case_id"`
+	} `json:"cases"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func (p casesPage) ids() []int {
+	ids := []int{}
+	for _, c := range p.Cases {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestFilterPages(t *testing.T) {
+	c := fakeFilter(t)
+	get := func(query map[string]string, cursor string, fetchAll bool, limit int) casesPage {
+		t.Helper()
+		data, err := filterPages(context.Background(), c, "/manage/cases/filter", "cases", query, cursor, fetchAll, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p casesPage
+		if err := json.Unmarshal(data, &p); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		return p
+	}
+
+	for _, tt := range []struct {
+		name     string
+		query    map[string]string
+		fetchAll bool
+		limit    int
+		want     [][]int // ids of each part, following next_cursor
+	}{
+		{"null page", map[string]string{"case_name": "null"}, false, 0, [][]int{{}}},
+		{"empty page", map[string]string{"case_name": "empty"}, true, 0, [][]int{{}}},
+		{"cursor round-trip", map[string]string{"case_name": "x"}, false, 0, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"start page", map[string]string{"case_name": "x", "page": "2"}, false, 0, [][]int{{3, 4}, {5}}},
+		{"fetch_all", map[string]string{"case_name": "x"}, true, 0, [][]int{{1, 2, 3, 4, 5}}},
+		{"fetch_all stops mid-page", map[string]string{"case_name": "x", "per_page": "2"}, true, 3, [][]int{{1, 2, 3}, {4, 5}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			query, cursor := tt.query, ""
+			for i, want := range tt.want {
+				p := get(query, cursor, tt.fetchAll, tt.limit)
+				if got := p.ids(); !reflect.DeepEqual(got, want) {
+					t.Fatalf("part %d = %v, want %v", i+1, got, want)
+				}
+				if len(want) > 0 && p.Total != 5 {
+					t.Errorf("part %d: total = %d, want 5", i+1, p.Total)
+				}
+				last := i == len(tt.want)-1
+				if (p.NextCursor == "") != last {
+					t.Fatalf("part %d: next_cursor = %q", i+1, p.NextCursor)
+				}
+				// The cursor carries the query, which need not be repeated.
+				query, cursor = nil, p.NextCursor
+			}
+		})
+	}
+
+	if _, err := filterPages(context.Background(), c, "/manage/cases/filter", "cases", nil, "bogus", false, 0); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+		t.Errorf("bogus cursor: error = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// redactResults wraps h so that error messages are masked with every
+// redaction rule, and output with the rules applying to it, before they
+// reach the model. Output that is JSON keeps its structure; structured
+// content that cannot be masked is dropped rather than sent unmasked.
+func redactResults[In any](ts *toolset, h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	rd := ts.opts.Redactor
+	if rd == nil {
+		return h
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		res, out, err := h(ctx, req, in)
+		if err != nil || res == nil {
+			return res, out, err
+		}
+		if res.IsError {
+			if te, ok := toolErrorOf(res); ok {
+				masked := *te
+				masked.Message = rd.String(te.Message)
+				masked.Hint = rd.String(te.Hint)
+				if te.Fields != nil {
+					masked.Fields = make(map[string][]string, len(te.Fields))
+					for name, msgs := range te.Fields {
+						for _, m := range msgs {
+							masked.Fields[name] = append(masked.Fields[name], rd.String(m))
+						}
+					}
+				}
+				return masked.result(), out, nil
+			}
+			for _, c := range res.Content {
+				if t, ok := c.(*mcp.TextContent); ok {
+					t.Text = rd.String(t.Text)
+				}
+			}
+			return res, out, nil
+		}
+		if !rd.MasksOutput() {
+			return res, out, nil
+		}
+		for _, c := range res.Content {
+			if t, ok := c.(*mcp.TextContent); ok {
+				t.Text = string(rd.OutputData([]byte(t.Text)))
+			}
+		}
+		if out == nil {
+			return res, nil, nil
+		}
+		b, err := json.Marshal(out)
+		if err != nil {
+			return res, nil, nil
+		}
+		masked, ok := rd.OutputJSON(b)
+		if !ok {
+			return res, nil, nil
+		}
+		if bytes.Equal(masked, b) {
+			return res, out, nil
+		}
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(masked))
+		dec.UseNumber()
+		if dec.Decode(&v) != nil {
+			return res, nil, nil
+		}
+		return res, v, nil
+	}
+}
@@ -1,31 +1,240 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"log/slog"
+	"maps"
+	"path"
+	"reflect"
+	"strings"
 
+	"dfir-iris-mcp/internal/audit"
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/redact"
+	"dfir-iris-mcp/internal/telemetry"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func RegisterAll(s *mcp.Server, c *client.Client) {
-	registerSystem(s, c)
-	registerSettings(s, c)
-	registerCases(s, c)
-	registerAlerts(s, c)
-	registerAssets(s, c)
-	registerNotes(s, c)
-	registerIOCs(s, c)
-	registerTimeline(s, c)
-	registerTasks(s, c)
-	registerEvidences(s, c)
-	registerDatastore(s, c)
-	registerComments(s, c)
-	registerUsers(s, c)
-	registerGroups(s, c)
-	registerCustomers(s, c)
+// Options controls which tools RegisterAll exposes.
+type Options struct {
+	// ReadOnly registers only tools that never modify IRIS.
+	ReadOnly bool
+	// Include, if non-empty, registers only tools matching one of its
+	// entries. Exclude then removes matching tools. An entry is either a
+	// domain name such as "cases" or a glob on the tool name such as
+	// "dfir_iris_*_delete".
+	Include []string
+	Exclude []string
+	// Primary names the instance served by the Client given to RegisterAll.
+	Primary string
+	// Instances are further IRIS deployments. When set, every tool accepts
+	// an instance argument selecting the deployment to call.
+	Instances []Instance
+	// MaxOutputBytes is the size above which read tool output is truncated
+	// with a continuation token. Zero disables truncation.
+	MaxOutputBytes int
+	// FetchAllLimit caps the items a fetch_all filter call collects. Zero
+	// means no cap.
+	FetchAllLimit int
+	// Audit, if set, records every tool call.
+	Audit *audit.Logger
+	// Redactor, if set, masks error messages and tool output.
+	Redactor *redact.Redactor
+	// Metrics, if set, counts tool calls and their errors.
+	Metrics *telemetry.Metrics
+	// Logger logs tool calls. If nil, the default logger is used. Records
+	// sent to MCP clients are masked by Redactor, but those of Logger are
+	// not: mask them with logging.Redact.
+	Logger *slog.Logger
+}
+
+// domains lists the tool groups in registration order. The names are the
+// domains accepted by Options.Include and Options.Exclude.
+var domains = []struct {
+	name     string
+	register func(*toolset, *client.Client)
+}{
+	{"system", registerSystem},
+	{"instances", registerInstances},
+	{"settings", registerSettings},
+	{"cases", registerCases},
+	{"alerts", registerAlerts},
+	{"assets", registerAssets},
+	{"notes", registerNotes},
+	{"iocs", registerIOCs},
+	{"timeline", registerTimeline},
+	{"tasks", registerTasks},
+	{"evidences", registerEvidences},
+	{"datastore", registerDatastore},
+	{"comments", registerComments},
+	{"users", registerUsers},
+	{"groups", registerGroups},
+	{"customers", registerCustomers},
+}
+
+// RegisterAll registers the tools selected by opts. It fails if an
+// Include or Exclude entry matches no tool, which is almost always a typo.
+func RegisterAll(s *mcp.Server, c *client.Client, opts Options) error {
+	if opts.Primary == "" {
+		opts.Primary = "default"
+	}
+	ts := &toolset{
+		server:        s,
+		opts:          opts,
+		matched:       make(map[string]bool),
+		confirmations: newConfirmations(),
+	}
+	for _, d := range domains {
+		ts.domain = d.name
+		d.register(ts, c)
+	}
+	for _, pattern := range append(opts.Include, opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+		if !ts.matched[pattern] {
+			return fmt.Errorf("tool pattern %q matches no domain or tool", pattern)
+		}
+	}
+	return nil
+}
+
+// toolKind classifies a tool by its effect on IRIS data.
+type toolKind int
+
+const (
+	toolRead        toolKind = iota // list, get, filter, export, search
+	toolWrite                       // create or modify objects
+	toolDestructive                 // delete objects
+)
+
+func (k toolKind) annotations() *mcp.ToolAnnotations {
+	destructive := k == toolDestructive
+	return &mcp.ToolAnnotations{
+		ReadOnlyHint:    k == toolRead,
+		DestructiveHint: &destructive,
+	}
+}
+
+// toolset is the registration target shared by the register* functions.
+type toolset struct {
+	server  *mcp.Server
+	opts    Options
+	domain  string          // domain currently being registered
+	matched map[string]bool // filter entries that matched at least one tool
+
+	confirmations *confirmations
+}
+
+// addTool registers a tool of the given kind unless the options exclude it.
+//
+// Out is any for tools that only return text, or a pointer to a model type
+// whose schema becomes the tool's output schema. A nil Out carries no
+// structured content, nor does an error result of such a tool.
+func addTool[In, Out any](ts *toolset, kind toolKind, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	if !ts.selected(t.Name, kind) {
+		return
+	}
+	t.Annotations = kind.annotations()
+	handler := redactResults(ts, explainErrors(withOutputSchema(t, h)))
+	args := make(map[string]*jsonschema.Schema)
+	if kind == toolRead && t.Name != instancesListTool {
+		maps.Copy(args, shapeArgsSchema)
+		handler = shapeOutput(ts, handler, outputDecoder[Out]())
+	}
+	if len(ts.opts.Instances) > 0 && t.Name != instancesListTool {
+		args["instance"] = ts.instanceArg()
+		handler = routeInstance(ts, handler)
+	}
+	if t.OutputSchema != nil {
+		handler = errorsOutsideSchema(handler)
+	}
+	withArgs[In](t, args)
+	mcp.AddTool(ts.server, t, instrumented(ts, t.Name, audited(ts, t.Name, handler)))
+}
+
+// withArgs sets t's input schema to the one inferred from In, using
+// argTypeSchemas, plus the optional arguments in extra, which are handled by
+// wrappers around the tool handler rather than by the handler itself.
+func withArgs[In any](t *mcp.Tool, extra map[string]*jsonschema.Schema) {
+	schema, err := jsonschema.For[In](&jsonschema.ForOptions{TypeSchemas: argTypeSchemas})
+	if err != nil {
+		panic(fmt.Sprintf("tool %s: input schema: %v", t.Name, err))
+	}
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*jsonschema.Schema)
+	}
+	for name, prop := range extra {
+		if _, ok := schema.Properties[name]; ok {
+			panic(fmt.Sprintf("tool %s: argument %q is reserved", t.Name, name))
+		}
+		schema.Properties[name] = prop
+	}
+	t.InputSchema = schema
+}
+
+// withOutputSchema sets t's output schema from Out and adapts h to the
+// untyped handler the rest of the registration works with. The SDK would
+// send the zero value of a nil *Out as structured content, so the schema is
+// set here and nil outputs are dropped instead.
+func withOutputSchema[In, Out any](t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, any] {
+	if untyped, ok := any(h).(mcp.ToolHandlerFor[In, any]); ok {
+		return untyped
+	}
+	rt := reflect.TypeFor[Out]()
+	if rt.Kind() != reflect.Pointer {
+		panic(fmt.Sprintf("tool %s: output type %v is not a pointer", t.Name, rt))
+	}
+	schema, err := jsonschema.ForType(rt.Elem(), &jsonschema.ForOptions{})
+	if err != nil {
+		panic(fmt.Sprintf("tool %s: output schema: %v", t.Name, err))
+	}
+	t.OutputSchema = schema
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		res, out, err := h(ctx, req, in)
+		var none Out
+		if err != nil || (res != nil && res.IsError) || any(out) == any(none) {
+			return res, nil, err
+		}
+		return res, out, nil
+	}
+}
+
+func (ts *toolset) logger() *slog.Logger {
+	if ts.opts.Logger != nil {
+		return ts.opts.Logger
+	}
+	return slog.Default()
+}
+
+func (ts *toolset) selected(name string, kind toolKind) bool {
+	// Evaluate every pattern so that matched is complete for RegisterAll.
+	included := len(ts.opts.Include) == 0
+	if ts.match(ts.opts.Include, name) {
+		included = true
+	}
+	excluded := ts.match(ts.opts.Exclude, name)
+	if ts.opts.ReadOnly && kind != toolRead {
+		return false
+	}
+	return included && !excluded
+}
+
+// match reports whether name or the current domain matches any of patterns.
+func (ts *toolset) match(patterns []string, name string) bool {
+	found := false
+	for _, p := range patterns {
+		ok, _ := path.Match(p, name)
+		if ok || strings.EqualFold(p, ts.domain) {
+			ts.matched[p] = true
+			found = true
+		}
+	}
+	return found
 }
 
 func textResult(data json.RawMessage) *mcp.CallToolResult {
@@ -34,15 +243,31 @@ func textResult(data json.RawMessage) *mcp.CallToolResult {
 	}
 }
 
-func errorResult(err error) *mcp.CallToolResult {
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-		IsError: true,
+// outputDecoder returns a function decoding JSON into a new *Out, or nil if
+// Out is any.
+func outputDecoder[Out any]() func([]byte) (any, bool) {
+	rt := reflect.TypeFor[Out]()
+	if rt.Kind() != reflect.Pointer {
+		return nil
+	}
+	return func(data []byte) (any, bool) {
+		out := reflect.New(rt.Elem()).Interface()
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, false
+		}
+		return out, true
 	}
 }
 
-func cidQuery(caseID int) map[string]string {
-	return map[string]string{"cid": strconv.Itoa(caseID)}
+// structuredResult returns data as text, exactly as IRIS sent it, along with
+// its decoding into T as structured content. If T cannot decode data, for
+// example because of an unexpected IRIS version, only the text is returned.
+func structuredResult[T any](data json.RawMessage) (*mcp.CallToolResult, *T, error) {
+	out := new(T)
+	if err := json.Unmarshal(data, out); err != nil {
+		return textResult(data), nil, nil
+	}
+	return textResult(data), out, nil
 }
 
 // toBody converts a struct to a map for use as a JSON request body,
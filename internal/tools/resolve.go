@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/lookup"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// nameOrID is an argument holding the ID of an IRIS setting, such as an IOC
+// type, which callers may also give by name. resolveName turns a name into the
+// ID before the argument is sent to IRIS.
+type nameOrID struct {
+	ID   int
+	Name string
+}
+
+func (v *nameOrID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		*v = nameOrID{}
+		return json.Unmarshal(b, &v.ID)
+	}
+	s = strings.TrimSpace(s)
+	if id, err := strconv.Atoi(s); err == nil {
+		*v = nameOrID{ID: id}
+	} else {
+		*v = nameOrID{Name: s}
+	}
+	return nil
+}
+
+func (v nameOrID) MarshalJSON() ([]byte, error) {
+	if v.Name != "" {
+		return nil, fmt.Errorf("unresolved name %q", v.Name)
+	}
+	return json.Marshal(v.ID)
+}
+
+// argTypeSchemas overrides the inferred schema of argument types.
+var argTypeSchemas = map[reflect.Type]*jsonschema.Schema{
+	reflect.TypeFor[nameOrID](): {Types: []string{"integer", "string"}},
+}
+
+// resolveName sets the ID of v, the argument named arg, from its name if it was
+// given one. A nil v is an omitted argument and left alone.
+func resolveName(ctx context.Context, c *client.Client, arg string, v *nameOrID) error {
+	if v == nil || v.Name == "" {
+		return nil
+	}
+	entries, err := lookup.List(ctx, c, lookup.Sources[arg])
+	if err != nil {
+		return fmt.Errorf("resolving %s %q: %w", arg, v.Name, err)
+	}
+	id, err := lookup.Resolve(entries, arg, v.Name)
+	if err != nil {
+		return err
+	}
+	*v = nameOrID{ID: id}
+	return nil
+}
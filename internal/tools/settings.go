@@ -2,13 +2,14 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 
 	"dfir-iris-mcp/internal/client"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerSettings(s *mcp.Server, c *client.Client) {
+func registerSettings(ts *toolset, c *client.Client) {
 	settings := []struct {
 		name string
 		desc string
@@ -27,7 +28,7 @@ func registerSettings(s *mcp.Server, c *client.Client) {
 
 	for _, st := range settings {
 		st := st
-		mcp.AddTool(s, &mcp.Tool{
+		addTool(ts, toolRead, &mcp.Tool{
 			Name:        st.name,
 			Description: st.desc,
 		}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -38,4 +39,17 @@ func registerSettings(s *mcp.Server, c *client.Client) {
 			return textResult(data), nil, nil
 		})
 	}
+
+	// Refresh the settings cache
+	addTool(ts, toolRead, &mcp.Tool{
+		Name:        "dfir_iris_settings_refresh",
+		Description: "Reload the cached settings lists from IRIS, e.g. after an administrator added an IOC type, and report the cache hit rate",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		stats, err := c.RefreshSettings(ctx)
+		if err != nil {
+			return errorResult(err), nil, nil
+		}
+		data, _ := json.Marshal(map[string]any{"refreshed": len(client.SettingsPaths), "cache_before_refresh": stats})
+		return textResult(data), nil, nil
+	})
 }
@@ -0,0 +1,396 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// summaryStringLimit is the length, in runes, to which summary mode shortens
+// strings.
+const summaryStringLimit = 200
+
+// shapeArgs are the optional arguments read tools accept to reduce the size
+// of their output.
+type shapeArgs struct {
+	Fields       []string `json:"fields"`
+	Summary      bool     `json:"summary"`
+	Continuation string   `json:"continuation"`
+}
+
+var shapeArgsSchema = map[string]*jsonschema.Schema{
+	"fields": {
+		Type:        "array",
+		Items:       &jsonschema.Schema{Type: "string"},
+		Description: `Only return these fields of each object, e.g. ["case_id", "name"]`,
+	},
+	"summary": {
+		Type:        "boolean",
+		Description: "Compact output: long strings are shortened and lists or objects nested in list items are replaced by their size",
+	},
+	"continuation": {
+		Type:        "string",
+		Description: "Token from a truncated previous response, to fetch the next part. Repeat the other arguments unchanged",
+	},
+}
+
+// shapeOutput wraps h so that its text output honours the shaping arguments
+// and the output budget. Structured content is decoded again from the shaped
+// JSON with decode, so that both stay consistent, and dropped unless that
+// decoding is faithful to the full output: a chunk of JSON, strings shortened
+// or replaced in summary mode and fields left out that the output type
+// reports as zero would otherwise reach the client as garbage values.
+func shapeOutput[In any](ts *toolset, h mcp.ToolHandlerFor[In, any], decode func([]byte) (any, bool)) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		var args shapeArgs
+		_ = json.Unmarshal(req.Params.Arguments, &args)
+		res, out, err := h(ctx, req, in)
+		if err != nil || res == nil || res.IsError || len(res.Content) != 1 {
+			return res, out, err
+		}
+		text, ok := res.Content[0].(*mcp.TextContent)
+		if !ok {
+			return res, out, nil
+		}
+		shaped, err := shape([]byte(text.Text), args, ts.opts.MaxOutputBytes)
+		if err != nil {
+			return errorResult(err), nil, nil
+		}
+		if shaped == nil {
+			return res, out, nil
+		}
+		res.Content = []mcp.Content{&mcp.TextContent{Text: string(shaped.text)}}
+		if shaped.note != "" {
+			res.Content = append(res.Content, &mcp.TextContent{Text: shaped.note})
+		}
+		if out == nil || decode == nil || !shaped.isJSON {
+			return res, nil, nil
+		}
+		v, ok := decode(shaped.text)
+		if !ok || !encodesWithin(v, out) {
+			return res, nil, nil
+		}
+		return res, v, nil
+	}
+}
+
+// encodesWithin reports whether the JSON encoding of part only holds values
+// of the encoding of whole: its fields have the same values and its lists
+// hold items of the same lists, in order, as when a page is cut from them.
+func encodesWithin(part, whole any) bool {
+	var p, w any
+	for _, x := range []struct {
+		v   any
+		dst *any
+	}{{part, &p}, {whole, &w}} {
+		b, err := json.Marshal(x.v)
+		if err != nil || json.Unmarshal(b, x.dst) != nil {
+			return false
+		}
+	}
+	return within(p, w)
+}
+
+func within(part, whole any) bool {
+	switch part := part.(type) {
+	case map[string]any:
+		obj, ok := whole.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range part {
+			if w, ok := obj[k]; !ok || !within(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		items, ok := whole.([]any)
+		if !ok {
+			return false
+		}
+		j := 0
+		for _, v := range part {
+			for j < len(items) && !within(v, items[j]) {
+				j++
+			}
+			if j == len(items) {
+				return false
+			}
+			j++
+		}
+		return true
+	}
+	return reflect.DeepEqual(part, whole)
+}
+
+// shapedOutput is the result of shape.
+type shapedOutput struct {
+	text   []byte
+	isJSON bool   // text is a JSON document, not a chunk of one
+	note   string // explains a truncation
+}
+
+// continuation is the decoded form of a continuation token. Truncated JSON
+// continues at item Offset of the array at Path; text that has no array to
+// cut continues at byte Byte.
+type continuation struct {
+	Path   []string `json:"p,omitempty"`
+	Offset int      `json:"o,omitempty"`
+	Byte   int      `json:"b,omitempty"`
+}
+
+func (c continuation) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeContinuation(token string) (continuation, error) {
+	var c continuation
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || c.Offset < 0 || c.Byte < 0 {
+		return c, errors.New("invalid continuation token: pass the token from the previous response unchanged")
+	}
+	return c, nil
+}
+
+// shape applies args and the byte budget to data. It returns nil if data is
+// to be returned as is.
+func shape(data []byte, args shapeArgs, budget int) (*shapedOutput, error) {
+	var cont continuation
+	if args.Continuation != "" {
+		var err error
+		if cont, err = decodeContinuation(args.Continuation); err != nil {
+			return nil, err
+		}
+	}
+	if len(args.Fields) == 0 && !args.Summary && args.Continuation == "" && (budget <= 0 || len(data) <= budget) {
+		return nil, nil
+	}
+
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return chunkText(data, cont.Byte, budget), nil
+	}
+	if len(args.Fields) > 0 {
+		keep := make(map[string]bool, len(args.Fields))
+		for _, f := range args.Fields {
+			keep[f] = true
+		}
+		doc = project(doc, keep)
+	}
+	if args.Summary {
+		doc = compact(doc, false)
+	}
+
+	text, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if cont.Path == nil && cont.Offset == 0 && cont.Byte == 0 && (budget <= 0 || len(text) <= budget) {
+		return &shapedOutput{text: text, isJSON: true}, nil
+	}
+	if cont.Byte > 0 {
+		return chunkText(text, cont.Byte, budget), nil
+	}
+
+	path := cont.Path
+	if path == nil {
+		path = largestArray(doc, nil)
+	}
+	items, ok := arrayAt(doc, path)
+	if !ok && cont.Path != nil {
+		return nil, fmt.Errorf("invalid continuation token: the response no longer has a list at %q", strings.Join(cont.Path, "."))
+	}
+	if !ok || len(items) < 2 && cont.Path == nil {
+		// Nothing to paginate, e.g. a single huge note.
+		return chunkText(text, 0, budget), nil
+	}
+	if cont.Offset > len(items) {
+		return nil, fmt.Errorf("invalid continuation token: offset %d is beyond the %d items of the list", cont.Offset, len(items))
+	}
+
+	// Take as many items from the offset as fit next to the rest of the
+	// document, and always at least one so that iteration progresses.
+	doc = setArrayAt(doc, path, []any{})
+	rest, _ := json.Marshal(doc)
+	size, n := len(rest), 0
+	for _, item := range items[cont.Offset:] {
+		b, _ := json.Marshal(item)
+		if n > 0 && budget > 0 && size+len(b)+1 > budget {
+			break
+		}
+		size += len(b) + 1
+		n++
+	}
+	end := cont.Offset + n
+	doc = setArrayAt(doc, path, items[cont.Offset:end])
+	if text, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	out := &shapedOutput{text: text, isJSON: true}
+	where := strings.Join(path, ".")
+	if where == "" {
+		where = "the result"
+	}
+	if end < len(items) {
+		out.note = fmt.Sprintf("Output truncated to fit the size limit: items %d-%d of %d in %s. "+
+			"Call again with the same arguments and continuation %q for the next items, "+
+			"or use fields/summary to request less data.",
+			cont.Offset+1, end, len(items), where, continuation{Path: path, Offset: end}.encode())
+	} else if cont.Offset > 0 {
+		out.note = fmt.Sprintf("Items %d-%d of %d in %s; this is the last part.", cont.Offset+1, end, len(items), where)
+	}
+	return out, nil
+}
+
+// chunkText returns up to budget bytes of text from offset, cut at a rune
+// boundary, for output that has no list to paginate.
+func chunkText(text []byte, offset, budget int) *shapedOutput {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	end := len(text)
+	if budget > 0 && offset+budget < end {
+		end = offset + budget
+		for end > offset && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+	out := &shapedOutput{text: text[offset:end], isJSON: offset == 0 && end == len(text)}
+	if end < len(text) {
+		out.note = fmt.Sprintf("Output truncated to fit the size limit: bytes %d-%d of %d. "+
+			"Call again with the same arguments and continuation %q for the next part.",
+			offset, end, len(text), continuation{Byte: end}.encode())
+	}
+	return out
+}
+
+// project keeps only the keys in keep of the objects that have any of them,
+// and looks for such objects in the values of the objects that have none.
+func project(v any, keep map[string]bool) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = project(v[i], keep)
+		}
+		return v
+	case map[string]any:
+		kept := make(map[string]any)
+		for k, val := range v {
+			if keep[k] {
+				kept[k] = val
+			}
+		}
+		if len(kept) > 0 {
+			return kept
+		}
+		for k, val := range v {
+			v[k] = project(val, keep)
+		}
+		return v
+	}
+	return v
+}
+
+// compact shortens long strings and, within list items, replaces nested
+// lists and objects by a description of their size.
+func compact(v any, inItem bool) any {
+	switch v := v.(type) {
+	case string:
+		if utf8.RuneCountInString(v) > summaryStringLimit {
+			return string([]rune(v)[:summaryStringLimit]) + "…"
+		}
+		return v
+	case []any:
+		if inItem {
+			return fmt.Sprintf("[%d items]", len(v))
+		}
+		for i := range v {
+			v[i] = compactItem(v[i])
+		}
+		return v
+	case map[string]any:
+		if inItem {
+			return fmt.Sprintf("{%d fields}", len(v))
+		}
+		for k, val := range v {
+			v[k] = compact(val, false)
+		}
+		return v
+	}
+	return v
+}
+
+func compactItem(v any) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return compact(v, false)
+	}
+	for k, val := range obj {
+		obj[k] = compact(val, true)
+	}
+	return obj
+}
+
+// largestArray returns the path of the array in v with the longest JSON
+// encoding, or nil if there is none. Only object keys are followed, in
+// sorted order so that ties resolve the same way on every call.
+func largestArray(v any, path []string) []string {
+	var best []string
+	bestSize := -1
+	var walk func(v any, path []string)
+	walk = func(v any, path []string) {
+		switch v := v.(type) {
+		case []any:
+			b, _ := json.Marshal(v)
+			if len(b) > bestSize {
+				best, bestSize = append([]string{}, path...), len(b)
+			}
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				walk(v[k], append(path, k))
+			}
+		}
+	}
+	walk(v, path)
+	return best
+}
+
+func arrayAt(v any, path []string) ([]any, bool) {
+	for _, k := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		v = obj[k]
+	}
+	items, ok := v.([]any)
+	return items, ok
+}
+
+// setArrayAt replaces the value at path, which arrayAt has found, by items.
+func setArrayAt(v any, path []string, items []any) any {
+	if len(path) == 0 {
+		return items
+	}
+	obj := v.(map[string]any)
+	obj[path[0]] = setArrayAt(obj[path[0]], path[1:], items)
+	return obj
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dfir-iris-mcp/internal/model"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestShape(t *testing.T) {
+	// Two lists: the larger one, "iocs", is the one that gets cut.
+	doc := `{"case_id":1,"tags":["a","b"],"iocs":[{"id":1,"v":"aaaaaaaaaa"},{"id":2,"v":"bbbbbbbbbb"},{"id":3,"v":"cccccccccc"},{"id":4,"v":"dddddddddd"}]}`
+	iocs := continuation{Path: []string{"iocs"}, Offset: 2}.encode()
+	// Text that is not JSON; the 4th byte starts the two-byte "é".
+	text := "abcé" + strings.Repeat("x", 20)
+
+	for _, tt := range []struct {
+		name    string
+		data    string
+		args    shapeArgs
+		budget  int
+		want    string // shaped text; empty if shape returns nil
+		isJSON  bool
+		next    *continuation // continuation offered in the note
+		wantErr string
+	}{{
+		name:   "within budget",
+		data:   doc,
+		budget: 1000,
+	}, {
+		name:   "array cut at the largest array",
+		data:   doc,
+		budget: 100,
+		want:   `{"case_id":1,"iocs":[{"id":1,"v":"aaaaaaaaaa"},{"id":2,"v":"bbbbbbbbbb"}],"tags":["a","b"]}`,
+		isJSON: true,
+		next:   &continuation{Path: []string{"iocs"}, Offset: 2},
+	}, {
+		name:   "resume at offset",
+		data:   doc,
+		args:   shapeArgs{Continuation: iocs},
+		budget: 100,
+		want:   `{"case_id":1,"iocs":[{"id":3,"v":"cccccccccc"},{"id":4,"v":"dddddddddd"}],"tags":["a","b"]}`,
+		isJSON: true,
+	}, {
+		name:   "at least one item per part",
+		data:   doc,
+		args:   shapeArgs{Continuation: continuation{Path: []string{"iocs"}, Offset: 1}.encode()},
+		budget: 10,
+		want:   `{"case_id":1,"iocs":[{"id":2,"v":"bbbbbbbbbb"}],"tags":["a","b"]}`,
+		isJSON: true,
+		next:   &continuation{Path: []string{"iocs"}, Offset: 2},
+	}, {
+		name:   "fields",
+		data:   doc,
+		args:   shapeArgs{Fields: []string{"id"}},
+		want:   `{"case_id":1,"iocs":[{"id":1},{"id":2},{"id":3},{"id":4}],"tags":["a","b"]}`,
+		isJSON: true,
+	}, {
+		name:   "summary",
+		data:   `[{"id":1,"tags":["a","b","c"],"owner":{"id":2,"name":"x"}}]`,
+		args:   shapeArgs{Summary: true},
+		want:   `[{"id":1,"owner":"{2 fields}","tags":"[3 items]"}]`,
+		isJSON: true,
+	}, {
+		name:   "bytes cut at a rune boundary",
+		data:   text,
+		budget: 4,
+		want:   "abc",
+		next:   &continuation{Byte: 3},
+	}, {
+		name:   "bytes resumed",
+		data:   text,
+		args:   shapeArgs{Continuation: continuation{Byte: 3}.encode()},
+		budget: 4,
+		want:   "éxx",
+		next:   &continuation{Byte: 7},
+	}, {
+		name:    "malformed token",
+		data:    doc,
+		args:    shapeArgs{Continuation: "not a token!"},
+		wantErr: "invalid continuation token",
+	}, {
+		name:    "token for a list that is gone",
+		data:    `{"case_id":1,"iocs":null}`,
+		args:    shapeArgs{Continuation: iocs},
+		wantErr: `no longer has a list at "iocs"`,
+	}, {
+		name:    "token beyond the list",
+		data:    `{"iocs":[1]}`,
+		args:    shapeArgs{Continuation: iocs},
+		wantErr: "offset 2 is beyond the 1 items",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shape([]byte(tt.data), tt.args, tt.budget)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("shape() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("shape() = %q, want nil", got.text)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("shape() = nil, want %q", tt.want)
+			}
+			if string(got.text) != tt.want || got.isJSON != tt.isJSON {
+				t.Errorf("shape() = %q (JSON %v), want %q (JSON %v)", got.text, got.isJSON, tt.want, tt.isJSON)
+			}
+			var next *continuation
+			if _, token, ok := strings.Cut(got.note, "continuation \""); ok {
+				token, _, _ = strings.Cut(token, "\"")
+				c, err := decodeContinuation(token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				next = &c
+			}
+			if !reflect.DeepEqual(next, tt.next) {
+				t.Errorf("continuation = %+v, want %+v (note %q)", next, tt.next, got.note)
+			}
+		})
+	}
+}
+
+func TestShapeOutputStructuredContent(t *testing.T) {
+	data := `{"case_id":7,"name":"Phishing","description":"` + strings.Repeat("d", summaryStringLimit+10) + `"}`
+	h := func(ctx context.Context, req *mcp.CallToolRequest, in any) (*mcp.CallToolResult, any, error) {
+		return structuredResult[model.Case](json.RawMessage(data))
+	}
+
+	for _, tt := range []struct {
+		name   string
+		args   string
+		budget int
+		want   bool // structured content is kept
+	}{
+		{"unshaped", `{}`, 0, true},
+		{"fields", `{"fields":["case_id","name"]}`, 0, true},
+		{"summary", `{"summary":true}`, 0, false},
+		{"chunk", `{}`, 100, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &toolset{opts: Options{MaxOutputBytes: tt.budget}}
+			req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tt.args)}}
+			res, out, err := shapeOutput(ts, h, outputDecoder[*model.Case]())(context.Background(), req, nil)
+			if err != nil || res.IsError {
+				t.Fatalf("handler failed: %v %+v", err, res)
+			}
+			if got := out != nil; got != tt.want {
+				t.Fatalf("structured content kept = %v, want %v: %+v", got, tt.want, out)
+			}
+			if tt.want && out.(*model.Case).ID != 7 {
+				t.Errorf("structured content = %+v", out)
+			}
+		})
+	}
+}
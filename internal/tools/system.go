@@ -8,8 +8,8 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerSystem(s *mcp.Server, c *client.Client) {
-	mcp.AddTool(s, &mcp.Tool{
+func registerSystem(ts *toolset, c *client.Client) {
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_system_ping",
 		Description: "Ping the DFIR-IRIS server to check connectivity",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -20,7 +20,7 @@ func registerSystem(s *mcp.Server, c *client.Client) {
 		return textResult(data), nil, nil
 	})
 
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_system_versions",
 		Description: "Get DFIR-IRIS server version information",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -5,24 +5,25 @@ import (
 	"fmt"
 
 	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/model"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerTasks(s *mcp.Server, c *client.Client) {
+func registerTasks(ts *toolset, c *client.Client) {
 	// List tasks
 	type tasksListArgs struct {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_tasks_list",
 		Description: "List all tasks in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksListArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Get(ctx, "/case/tasks/list", cidQuery(args.CaseID))
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksListArgs) (*mcp.CallToolResult, *model.TaskList, error) {
+		data, err := c.Get(ctx, "/case/tasks/list", client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.TaskList](data)
 	})
 
 	// Get task
@@ -30,16 +31,16 @@ func registerTasks(s *mcp.Server, c *client.Client) {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 		TaskID int `json:"task_id" jsonschema:"Task ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_tasks_get",
 		Description: "Get details of a specific task in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksGetArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksGetArgs) (*mcp.CallToolResult, *model.Task, error) {
 		path := fmt.Sprintf("/case/tasks/%d", args.TaskID)
-		data, err := c.Get(ctx, path, cidQuery(args.CaseID))
+		data, err := c.Get(ctx, path, client.CaseQuery(args.CaseID))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Task](data)
 	})
 
 	// Add task
@@ -51,15 +52,15 @@ func registerTasks(s *mcp.Server, c *client.Client) {
 		TaskStatusID    *int    `json:"task_status_id,omitempty" jsonschema:"Task status ID"`
 		TaskTags        *string `json:"task_tags,omitempty" jsonschema:"Comma-separated tags"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_tasks_add",
 		Description: "Add a new task to a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksAddArgs) (*mcp.CallToolResult, any, error) {
-		data, err := c.Post(ctx, "/case/tasks/add", cidQuery(args.CaseID), toBody(args, "case_id"))
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksAddArgs) (*mcp.CallToolResult, *model.Task, error) {
+		data, err := c.Post(ctx, "/case/tasks/add", client.CaseQuery(args.CaseID), toBody(args, "case_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Task](data)
 	})
 
 	// Update task
@@ -72,16 +73,16 @@ func registerTasks(s *mcp.Server, c *client.Client) {
 		TaskStatusID    *int    `json:"task_status_id,omitempty" jsonschema:"New status ID"`
 		TaskTags        *string `json:"task_tags,omitempty" jsonschema:"New comma-separated tags"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_tasks_update",
 		Description: "Update a task in a case",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksUpdateArgs) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksUpdateArgs) (*mcp.CallToolResult, *model.Task, error) {
 		path := fmt.Sprintf("/case/tasks/update/%d", args.TaskID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), toBody(args, "case_id", "task_id"))
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), toBody(args, "case_id", "task_id"))
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
-		return textResult(data), nil, nil
+		return structuredResult[model.Task](data)
 	})
 
 	// Delete task
@@ -89,12 +90,12 @@ func registerTasks(s *mcp.Server, c *client.Client) {
 		CaseID int `json:"case_id" jsonschema:"Case ID"`
 		TaskID int `json:"task_id" jsonschema:"Task ID to delete"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_tasks_delete",
 		Description: "Delete a task from a case",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args tasksDeleteArgs) (*mcp.CallToolResult, any, error) {
 		path := fmt.Sprintf("/case/tasks/delete/%d", args.TaskID)
-		data, err := c.Post(ctx, path, cidQuery(args.CaseID), nil)
+		data, err := c.Post(ctx, path, client.CaseQuery(args.CaseID), nil)
 		if err != nil {
 			return errorResult(err), nil, nil
 		}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("dfir-iris-mcp/internal/tools")
+
+// mask returns the function masking log records sent to MCP clients, or nil
+// if there is no redactor.
+func (ts *toolset) mask() func(string) string {
+	if ts.opts.Redactor == nil {
+		return nil
+	}
+	return ts.opts.Redactor.String
+}
+
+// instrumented wraps h so that every call of the tool is logged under a
+// request ID, traced, with the IRIS requests it sends as child spans, and
+// counted in the metrics if there are any. Log records of the call are also
+// sent to the MCP client, masked by the redactor, at the level it set with
+// logging/setLevel.
+func instrumented[In any](ts *toolset, name string, h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		requestID := logging.NewRequestID()
+		ctx, span := tracer.Start(ctx, "tools/call "+name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.tool.name", name),
+				attribute.String("request.id", requestID),
+			))
+		defer span.End()
+
+		log := ts.logger()
+		if req.Session != nil {
+			span.SetAttributes(attribute.String("mcp.session.id", req.Session.ID()))
+			toClient := mcp.NewLoggingHandler(req.Session, &mcp.LoggingHandlerOptions{
+				LoggerName: "dfir-iris-mcp",
+			})
+			log = slog.New(logging.Tee(log.Handler(), logging.Redact(toClient, ts.mask())))
+			log = log.With("session", req.Session.ID())
+		}
+		log = log.With("request_id", requestID, "tool", name)
+		ctx = logging.WithLogger(ctx, log)
+		log.DebugContext(ctx, "tool call started")
+
+		res, out, err := h(ctx, req, in)
+
+		var (
+			kind   client.ErrorKind
+			status int
+			msg    string
+		)
+		switch {
+		case err != nil:
+			kind, msg = client.Kind(err), err.Error()
+		case res != nil && res.IsError:
+			kind = client.KindUnknown
+			if te, ok := toolErrorOf(res); ok {
+				kind, status, msg = te.Kind, te.Status, te.Message
+			}
+		}
+		duration := time.Since(start)
+		if kind != "" {
+			span.SetAttributes(attribute.String("error.type", string(kind)))
+			span.SetStatus(codes.Error, string(kind))
+			log.WarnContext(ctx, "tool call failed", "duration", duration, "error_kind", kind, "status", status, "error", msg)
+		} else {
+			log.InfoContext(ctx, "tool call", "duration", duration)
+		}
+		if ts.opts.Metrics != nil {
+			ts.opts.Metrics.ToolCall(name, duration, kind, status)
+		}
+		return res, out, err
+	}
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dfir-iris-mcp/internal/client"
+	"dfir-iris-mcp/internal/telemetry"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstrumentedIRISNotFound(t *testing.T) {
+	exp := recordSpans(t)
+	metrics := telemetry.NewMetrics()
+
+	iris := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/case/ioc/77" {
+			t.Errorf("unexpected IRIS request %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":"error","message":"Not found","data":null}`))
+	}))
+	defer iris.Close()
+	c := client.New(iris.URL, "key", client.WithRequestObserver(metrics.RequestObserver("default")))
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	if err := RegisterAll(s, c, Options{Include: []string{"dfir_iris_iocs_get"}, Metrics: metrics}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "dfir_iris_iocs_get",
+		Arguments: map[string]any{"case_id": 3, "ioc_id": 77},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Fatalf("CallTool succeeded, want an error result")
+	}
+
+	spans := exp.GetSpans()
+	tool := findSpan(t, spans, "tools/call dfir_iris_iocs_get")
+	if tool.SpanKind != trace.SpanKindServer {
+		t.Errorf("tool span kind = %v, want server", tool.SpanKind)
+	}
+	wantAttrs(t, tool, map[attribute.Key]attribute.Value{
+		"mcp.tool.name": attribute.StringValue("dfir_iris_iocs_get"),
+		"error.type":    attribute.StringValue(string(client.KindNotFound)),
+	})
+	req := findSpan(t, spans, "GET /case/ioc/{id}")
+	if req.Parent.SpanID() != tool.SpanContext.SpanID() {
+		t.Errorf("IRIS request span is not a child of the tool span")
+	}
+	wantAttrs(t, req, map[attribute.Key]attribute.Value{
+		"http.request.method":       attribute.StringValue("GET"),
+		"http.route":                attribute.StringValue("/case/ioc/{id}"),
+		"url.path":                  attribute.StringValue("/case/ioc/77"),
+		"http.response.status_code": attribute.IntValue(http.StatusNotFound),
+	})
+
+	wantCounter(t, metrics, "dfir_iris_mcp_tool_errors_total",
+		map[string]string{"tool": "dfir_iris_iocs_get", "kind": "not_found", "status": "404"})
+	wantCounter(t, metrics, "dfir_iris_mcp_iris_requests_total",
+		map[string]string{"instance": "default", "method": "GET", "route": "/case/ioc/{id}", "status": "404"})
+}
+
+// recordSpans installs a global tracer provider recording spans in memory
+// for the rest of the test and returns the exporter holding them.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return exp
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	t.Fatalf("no span %q among %q", name, names)
+	return tracetest.SpanStub{}
+}
+
+func wantAttrs(t *testing.T, s tracetest.SpanStub, want map[attribute.Key]attribute.Value) {
+	t.Helper()
+	got := make(map[attribute.Key]attribute.Value)
+	for _, kv := range s.Attributes {
+		got[kv.Key] = kv.Value
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("span %q: %s = %v, want %v", s.Name, k, got[k].Emit(), v.Emit())
+		}
+	}
+}
+
+// wantCounter checks that the counter name with labels was incremented
+// exactly once.
+func wantCounter(t *testing.T, m *telemetry.Metrics, name string, labels map[string]string) {
+	t.Helper()
+	families, err := m.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range f.GetMetric() {
+			got := make(map[string]string)
+			for _, l := range metric.GetLabel() {
+				got[l.GetName()] = l.GetValue()
+			}
+			if len(got) != len(labels) {
+				continue
+			}
+			for k, v := range labels {
+				if got[k] != v {
+					continue metrics
+				}
+			}
+			if v := metric.GetCounter().GetValue(); v != 1 {
+				t.Errorf("%s%v = %v, want 1", name, labels, v)
+			}
+			return
+		}
+	}
+	t.Errorf("no %s%v", name, labels)
+}
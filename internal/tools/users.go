@@ -9,9 +9,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerUsers(s *mcp.Server, c *client.Client) {
+func registerUsers(ts *toolset, c *client.Client) {
 	// List users
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_users_list",
 		Description: "List all users in DFIR-IRIS",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -26,7 +26,7 @@ func registerUsers(s *mcp.Server, c *client.Client) {
 	type usersGetArgs struct {
 		UserID int `json:"user_id" jsonschema:"User ID"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolRead, &mcp.Tool{
 		Name:        "dfir_iris_users_get",
 		Description: "Get details of a specific user",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args usersGetArgs) (*mcp.CallToolResult, any, error) {
@@ -46,7 +46,7 @@ func registerUsers(s *mcp.Server, c *client.Client) {
 		UserPassword string  `json:"user_password" jsonschema:"Password for the user"`
 		UserIsAdmin  *bool   `json:"user_isadmin,omitempty" jsonschema:"Whether the user is an admin"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_users_add",
 		Description: "Create a new user (admin operation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args usersAddArgs) (*mcp.CallToolResult, any, error) {
@@ -65,7 +65,7 @@ func registerUsers(s *mcp.Server, c *client.Client) {
 		UserPassword *string `json:"user_password,omitempty" jsonschema:"New password"`
 		UserIsAdmin  *bool   `json:"user_isadmin,omitempty" jsonschema:"New admin status"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolWrite, &mcp.Tool{
 		Name:        "dfir_iris_users_update",
 		Description: "Update a user (admin operation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args usersUpdateArgs) (*mcp.CallToolResult, any, error) {
@@ -79,12 +79,20 @@ func registerUsers(s *mcp.Server, c *client.Client) {
 
 	// Delete user
 	type usersDeleteArgs struct {
-		UserID int `json:"user_id" jsonschema:"User ID to delete"`
+		UserID       int     `json:"user_id" jsonschema:"User ID to delete"`
+		ConfirmToken *string `json:"confirm_token,omitempty" jsonschema:"Token the user read from the server log to confirm the deletion"`
 	}
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(ts, toolDestructive, &mcp.Tool{
 		Name:        "dfir_iris_users_delete",
-		Description: "Delete a user (admin operation, irreversible)",
+		Description: "Delete a user (admin operation, irreversible, requires user confirmation)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args usersDeleteArgs) (*mcp.CallToolResult, any, error) {
+		target := fmt.Sprintf("deletion of user %d", args.UserID)
+		if res := ts.confirm(ctx, req, target, args.ConfirmToken, func() string {
+			return fmt.Sprintf("User %d will be permanently deleted.\n%s", args.UserID,
+				describeObject(ctx, c, fmt.Sprintf("/manage/users/%d", args.UserID), nil, "user_name", "user_login", "user_email"))
+		}); res != nil {
+			return res, nil, nil
+		}
 		path := fmt.Sprintf("/manage/users/delete/%d", args.UserID)
 		data, err := c.Post(ctx, path, nil, nil)
 		if err != nil {